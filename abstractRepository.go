@@ -1,16 +1,28 @@
 package stdlib
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"iter"
+	"maps"
 	"reflect"
+	"time"
 
-	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
-// ID is a generic type that represents the primary key of the entity, only accepting uint or uuid.UUID.
+// ID is a generic type that represents the primary key of the entity, usually an integer, a string or an uuid.UUID.
+// Entities with a composite primary key use a struct holding a field per primary key column,
+// named like the fields of the entity:
+//
+//	type MembershipKey struct {
+//		AccountID uint
+//		GroupID   uint
+//	}
 type ID interface {
-	int | int32 | int64 | uint | uint32 | uint64 | string | uuid.UUID
+	comparable
 }
 
 // Identifiable is a generic interface that represents an entity that has an ID.
@@ -19,214 +31,475 @@ type Identifiable[K ID] interface {
 }
 
 // T is a generic type that represents a database entity.
-// K is a generic type that represents the primary key of the entity, only accepting uint or uuid.UUID.
+// K is a generic type that represents the primary key of the entity, see ID.
 type AbstractRepository[T Identifiable[K], K ID] interface {
 
-	// FindAll retrieves all entities of type T from the database.
-	FindAll() ([]T, error)
+	// FindAll retrieves all entities of type T from the database, an empty table returns an empty slice.
+	// The results can be sorted with the OrderBy option.
+	FindAll(opts ...QueryOption) ([]T, error)
 
 	// FindByID retrieves a single entity of type T by its ID.
-	FindByID(id K) (T, error)
+	// The preloads can be customized with the WithPreload and WithoutPreloads options.
+	// Returns ErrRecordNotFound if there is no entity with the ID.
+	FindByID(id K, opts ...QueryOption) (T, error)
 
 	// FirstByKey retrieves a single entity of type T by a specific field (key),thats mean
 	// only the first Match!
 	// The `key` parameter specifies the field to search, and `value` is the value to match.
+	// The key must be a column (or field name) of T, otherwise ErrUnknownColumn is returned.
 	//
 	// if you want to find all use:
 	//	 FindAllByKey(key, value)
-	FirstByKey(key, value string) (T, error)
+	FirstByKey(key, value string, opts ...QueryOption) (T, error)
 
 	// FindAllByKey retrieves all entities of type T by a specific field (key)
 	// The `key` parameter specifies the field to search, and `value` is the value to match.
-	FindAllByKey(key, value string) ([]T, error)
+	// The key must be a column (or field name) of T, otherwise ErrUnknownColumn is returned.
+	FindAllByKey(key, value string, opts ...QueryOption) ([]T, error)
+
+	// FindWhere retrieves all entities of type T matching the filter, or an empty slice if none matches.
+	// A nil filter matches every entity.
+	FindWhere(filter Filter, opts ...QueryOption) ([]T, error)
+
+	// FindInto retrieves the entities of type T matching the filter into dest, a pointer to a slice of
+	// another type (e.g. a DTO). Unless the Select option is given, the columns of the fields of the
+	// destination type are selected, and they must be columns of T, otherwise ErrUnknownColumn is returned.
+	// Preloads are not applied. The typed helper FindAllAs is usually more convenient.
+	FindInto(dest any, filter Filter, opts ...QueryOption) error
+
+	// FirstWhere retrieves the first entity of type T matching the filter,
+	// by default the first by ID unless it is sorted with the OrderBy option.
+	// Returns ErrRecordNotFound if no entity matches.
+	FirstWhere(filter Filter, opts ...QueryOption) (T, error)
+
+	// CountWhere counts the entities of type T matching the filter.
+	CountWhere(filter Filter) (int64, error)
+
+	// ExistsWhere reports whether at least one entity of type T matches the filter.
+	ExistsWhere(filter Filter) (bool, error)
+
+	// Count counts every entity of type T, use CountWhere to count the ones matching a filter.
+	Count() (int64, error)
+
+	// ExistsByID reports whether there is an entity of type T with the ID.
+	ExistsByID(id K) (bool, error)
+
+	// Aggregate computes the aggregate function over the column for the entities of type T matching the filter
+	// and scans the result, aliased as `result`, into dest. When groupBy is not empty the result is computed
+	// per distinct value of the groupBy column, aliased as `group_value`, and dest must be a pointer to a slice.
+	// Both column and groupBy must be columns (or field names) of T, otherwise ErrUnknownColumn is returned;
+	// only AggregateCount accepts an empty column, counting every row.
+	//
+	// The typed helpers Sum, Min, Max, Avg and CountGroupBy are usually more convenient.
+	Aggregate(dest any, function AggregateFunction, column, groupBy string, filter Filter) error
+
+	// FindPage retrieves a page of entities of type T using offset/limit pagination,
+	// ordered by the OrderBy option (if any) and then by ID.
+	// The `page` parameter starts at 1, and `size` is the number of entities per page (at most MaxPageSize).
+	// The returned Page carries the total count of entities and whether there are more pages.
+	FindPage(page, size int, opts ...QueryOption) (Page[T], error)
+
+	// FindAfter retrieves up to `size` entities of type T (at most MaxPageSize) whose ID is greater than the one
+	// encoded in `cursor` (keyset pagination), ordered by ID. An empty cursor starts from the beginning.
+	// Use the NextCursor of the returned Page to fetch the following one.
+	FindAfter(cursor string, size int) (Page[T], error)
+
+	// FindInBatches walks every entity of type T matching the filter (nil matches all of them) in batches
+	// of `batchSize` entities (DefaultBatchSize if it is not positive), ordered by primary key with keyset
+	// pagination, so only one batch is kept in memory. fn is called with every batch, and the walk stops
+	// at the first error returned by fn or when the context of the repository (see WithContext) is cancelled.
+	FindInBatches(filter Filter, batchSize int, fn func(batch []T) error) error
+
+	// Iterate returns an iterator over every entity of type T matching the filter, loaded in batches
+	// like FindInBatches. A failed load is yielded as the last element with a non nil error.
+	//
+	//	for account, err := range repo.WithContext(ctx).Iterate(nil, 1000) {
+	//		if err != nil {
+	//			return err
+	//		}
+	//		...
+	//	}
+	Iterate(filter Filter, batchSize int) iter.Seq2[T, error]
 
 	// Create inserts a new entity of type T into the database and returns its ID.
+	// Create, Update, UpdateSpecific, Delete, HardDelete and Restore run the write hooks of the repository (see BeforeWrite).
 	// The operation can optionally be executed within a transaction.
 	Create(tx *gorm.DB, newEntity T) (T, error)
 
 	// Update modifies an existing entity of type T identified by its ID.
 	// If one of the parameter is null, it will be ignored! if you need to set a field to null, use UpdateSpecific instead
+	// If T implements Versioned, the row is only updated if its version matches the one of newEntity,
+	// otherwise ErrStaleEntity is returned. On success the version of newEntity is incremented.
+	// Returns ErrRecordNotFound if there is no entity with the ID.
 	// The operation can optionally be executed within a transaction.
 	Update(tx *gorm.DB, id K, newEntity T) error
 
 	// UpdateSpecific modifies an existing entity of type T identified by its ID. It only updates the fields specified in the map
+	// If T implements Versioned, the version is incremented and, when the map contains the "version" key,
+	// it is used as the expected version (see Update).
+	// Returns ErrRecordNotFound if there is no entity with the ID.
 	// The operation can optionally be executed within a transaction.
 	UpdateSpecific(tx *gorm.DB, id K, specificFields map[string]interface{}) error
 
+	// CreateMany inserts the entities of type T in batches of `batchSize` rows (DefaultBatchSize if it is not positive)
+	// and returns them with their generated IDs.
+	// The operation can optionally be executed within a transaction.
+	CreateMany(tx *gorm.DB, newEntities []T, batchSize int) ([]T, error)
+
+	// UpdateMany modifies the fields specified in the map of every entity of type T matching the filter
+	// and returns the number of affected rows. A nil filter is rejected by gorm (ErrMissingWhereClause).
+	// The operation can optionally be executed within a transaction.
+	UpdateMany(tx *gorm.DB, filter Filter, specificFields map[string]interface{}) (int64, error)
+
+	// DeleteMany marks every entity of type T matching the filter as deleted (soft delete)
+	// and returns the number of affected rows. A nil filter is rejected by gorm (ErrMissingWhereClause).
+	// The operation can optionally be executed within a transaction.
+	DeleteMany(tx *gorm.DB, filter Filter) (int64, error)
+
+	// DeleteByIDs marks the entities of type T with the given IDs as deleted (soft delete)
+	// and returns the number of affected rows.
+	// The operation can optionally be executed within a transaction.
+	DeleteByIDs(tx *gorm.DB, ids []K) (int64, error)
+
+	// RestoreByIDs unmarks the entities of type T with the given IDs as deleted (restore)
+	// and returns the number of affected rows.
+	// Returns ErrSoftDeleteUnsupported if T has no gorm.DeletedAt field.
+	// The operation can optionally be executed within a transaction.
+	RestoreByIDs(tx *gorm.DB, ids []K) (int64, error)
+
+	// Upsert inserts a new entity of type T or, when it conflicts with an existing row, updates it.
+	// See UpsertOptions to configure the conflict and the updated columns.
+	// A tenant scoped repository (see WithTenantScope) never updates the row of another tenant, it returns ErrRecordConflict.
+	// The operation can optionally be executed within a transaction.
+	Upsert(tx *gorm.DB, entity T, opts UpsertOptions) (T, error)
+
+	// UpsertMany inserts or updates the entities of type T in batches, see Upsert.
+	// The operation can optionally be executed within a transaction.
+	UpsertMany(tx *gorm.DB, entities []T, opts UpsertOptions) ([]T, error)
+
 	// Delete marks an entity of type T as deleted (soft delete) by its ID.
+	// Returns ErrRecordNotFound if there is no entity with the ID or it is already deleted.
 	// The operation can optionally be executed within a transaction.
 	Delete(tx *gorm.DB, id K) error
 
 	// Restore unmarks an entity of type T as deleted (restore) by its ID.
+	// Returns ErrRecordNotFound if there is no entity with the ID, deleted or not,
+	// and ErrSoftDeleteUnsupported if T has no gorm.DeletedAt field.
 	// The operation can optionally be executed within a transaction.
 	Restore(tx *gorm.DB, id K) error
 
+	// History retrieves the audit entries of the entity of type T with the ID, oldest first.
+	// It is empty unless the repository was created with the WithAudit option.
+	// A tenant scoped repository (see WithTenantScope) only returns the history of the entities of the tenant.
+	History(id K) ([]AuditEntry, error)
+
+	// FindDeleted retrieves all entities of type T marked as deleted (soft delete).
+	// Returns ErrSoftDeleteUnsupported if T has no gorm.DeletedAt field.
+	FindDeleted(opts ...QueryOption) ([]T, error)
+
+	// HardDelete permanently deletes an entity of type T by its ID, deleted or not.
+	// It runs the write hooks as an OperationHardDelete.
+	// Returns ErrRecordNotFound if there is no entity with the ID.
+	// The operation can optionally be executed within a transaction.
+	HardDelete(tx *gorm.DB, id K) error
+
+	// PurgeDeletedBefore permanently deletes the entities of type T marked as deleted before the given time
+	// and returns the number of affected rows, e.g. to enforce a retention window:
+	//
+	//	purged, err := repo.PurgeDeletedBefore(nil, time.Now().AddDate(0, 0, -30))
+	//
+	// Returns ErrSoftDeleteUnsupported if T has no gorm.DeletedAt field.
+	// The operation can optionally be executed within a transaction.
+	PurgeDeletedBefore(tx *gorm.DB, before time.Time) (int64, error)
+
 	// GetPreloads returns the default preloads for the repository.
 	// 	This need to be overriden by the concrete implementation!!
 	// by default is a empty string slice
+	// The finders can add to or drop them per call with the WithPreload and WithoutPreloads options.
 	GetPreloads() []string
 
+	// GetPrimaryKeys returns the primary key columns used to identify the entities by ID.
+	// By default they are derived from the GORM schema of T, override it in the concrete
+	// repository if they are different (e.g. a unique `account_id` column of a view).
+	GetPrimaryKeys() []string
+
 	// GetType returns the types defined of the repository.
 	GetType() string
 
 	// transactionCheck if is within a transactional context to use the
 	// transaction or use the current repository
 	TransactionCheck(tx *gorm.DB) *gorm.DB
+
+	// WithContext returns a view of the repository bound to ctx.
+	// Every query issued through the view (including the ones executed on a given tx)
+	// runs with gorm's WithContext, so the in-flight SQL is aborted when ctx is cancelled.
+	// Cancellation only reaches the SQL through a context that is actually cancelled: in a Fiber handler
+	// use c.UserContext() (cancelled by a middleware, e.g. with a timeout), not c.Context(), the fasthttp
+	// request context which is not cancelled when the client goes away and is recycled after the handler.
+	//
+	//	user, err := repo.WithContext(c.UserContext()).FindByID(id)
+	//
+	// If ctx carries a transaction (see ExecuteInTransactionContext), the view runs on it
+	// whenever no tx is given, so the services only need to pass ctx:
+	//
+	//	err := txRepo.ExecuteInTransactionContext(ctx, func(ctx context.Context) error {
+	//		_, err := accounts.WithContext(ctx).Create(nil, account)
+	//		return err
+	//	})
+	//
+	// The view only exposes the AbstractRepository methods, methods added by the
+	// concrete repository must be called on the concrete type.
+	WithContext(ctx context.Context) AbstractRepository[T, K]
 }
 
 type abstractRepositoryImpl[T Identifiable[K], K ID] struct {
-	gorm *gorm.DB
-	self AbstractRepository[T, K]
+	gorm  *gorm.DB
+	ctx   context.Context
+	self  AbstractRepository[T, K]
+	hooks writeHooks[T, K]
+
+	tenantColumn string
 }
 
 // FindAll implements AbstractRepository.
-func (repo *abstractRepositoryImpl[T, K]) FindAll() ([]T, error) {
-	var entities []T
-	var preloads []string
+func (repo *abstractRepositoryImpl[T, K]) FindAll(opts ...QueryOption) ([]T, error) {
+	return repo.FindWhere(nil, opts...)
+}
 
-	if repo.self == nil {
-		preloads = repo.GetPreloads()
-	} else {
-		preloads = repo.self.GetPreloads()
+// FindByID implements AbstractRepository.
+func (repo *abstractRepositoryImpl[T, K]) FindByID(id K, opts ...QueryOption) (T, error) {
+	var entity T
+
+	db, err := repo.query(nil, newQueryOptions(opts))
+	if err != nil {
+		return entity, err
 	}
 
-	db := applyPreloads(repo.gorm, preloads)
+	db, err = repo.byID(db, id)
+	if err != nil {
+		return entity, err
+	}
 
-	if err := db.Find(&entities).Error; err != nil {
+	if err := db.First(&entity).Error; err != nil {
+		return entity, repo.translate(err)
+	}
+	return entity, nil
+}
+
+// FirstByKey implements AbstractRepository.
+func (repo *abstractRepositoryImpl[T, K]) FirstByKey(key, value string, opts ...QueryOption) (T, error) {
+	return repo.FirstWhere(Eq(key, value), opts...)
+}
+
+// FindAllByKey implements AbstractRepository.
+func (repo *abstractRepositoryImpl[T, K]) FindAllByKey(key, value string, opts ...QueryOption) ([]T, error) {
+	return repo.FindWhere(Eq(key, value), opts...)
+}
+
+// FindWhere implements AbstractRepository.
+func (repo *abstractRepositoryImpl[T, K]) FindWhere(filter Filter, opts ...QueryOption) ([]T, error) {
+	var entities []T
+
+	db, err := repo.query(filter, newQueryOptions(opts))
+	if err != nil {
 		return nil, err
 	}
 
-	if len(entities) == 0 {
-		return nil, gorm.ErrRecordNotFound
+	if err := db.Find(&entities).Error; err != nil {
+		return entities, repo.translate(err)
 	}
+
 	return entities, nil
 }
 
-// FindByID implements AbstractRepository.
-func (repo *abstractRepositoryImpl[T, K]) FindByID(id K) (T, error) {
+// FirstWhere implements AbstractRepository.
+func (repo *abstractRepositoryImpl[T, K]) FirstWhere(filter Filter, opts ...QueryOption) (T, error) {
 	var entity T
-	var preloads []string
 
-	if repo.self == nil {
-		preloads = repo.GetPreloads()
-	} else {
-		preloads = repo.self.GetPreloads()
+	db, err := repo.query(filter, newQueryOptions(opts))
+	if err != nil {
+		return entity, err
 	}
 
-	db := applyPreloads(repo.gorm, preloads)
-
-	if err := db.Where("id = ?", id).First(&entity).Error; err != nil {
-		return entity, err
+	if err := db.First(&entity).Error; err != nil {
+		return entity, repo.translate(err)
 	}
 	return entity, nil
 }
 
-// FirstByKey implements AbstractRepository.
-func (repo *abstractRepositoryImpl[T, K]) FirstByKey(key, value string) (T, error) {
-	var entity T
-	var preloads []string
+// CountWhere implements AbstractRepository.
+func (repo *abstractRepositoryImpl[T, K]) CountWhere(filter Filter) (int64, error) {
+	var count int64
 
-	if repo.self == nil {
-		preloads = repo.GetPreloads()
-	} else {
-		preloads = repo.self.GetPreloads()
+	db, err := repo.model(nil, filter)
+	if err != nil {
+		return 0, err
 	}
 
-	db := applyPreloads(repo.gorm, preloads)
-	query := fmt.Sprintf("%s = ?", key)
-
-	if err := db.Where(query, value).First(&entity).Error; err != nil {
-		return entity, err
+	if err := db.Count(&count).Error; err != nil {
+		return 0, repo.translate(err)
 	}
-	return entity, nil
+	return count, nil
 }
 
-// FindByKey implements AbstractRepository.
-func (repo *abstractRepositoryImpl[T, K]) FindAllByKey(key, value string) ([]T, error) {
-	var entities []T
-	var preloads []string
+// ExistsWhere implements AbstractRepository.
+func (repo *abstractRepositoryImpl[T, K]) ExistsWhere(filter Filter) (bool, error) {
+	var hits []int
 
-	if repo.self == nil {
-		preloads = repo.GetPreloads()
-	} else {
-		preloads = repo.self.GetPreloads()
+	db, err := repo.model(nil, filter)
+	if err != nil {
+		return false, err
 	}
 
-	db := applyPreloads(repo.gorm, preloads)
-	query := fmt.Sprintf("%s = ?", key)
-
-	if err := db.Where(query, value).Find(&entities).Error; err != nil {
-		return entities, err
+	if err := db.Select("1").Limit(1).Find(&hits).Error; err != nil {
+		return false, repo.translate(err)
 	}
-
-	return entities, nil
+	return len(hits) > 0, nil
 }
 
 func (repo *abstractRepositoryImpl[T, K]) Create(tx *gorm.DB, newEntity T) (T, error) {
-	if err := repo.transCheck(tx).Create(&newEntity).Error; err != nil {
+	change := &Change[T, K]{Operation: OperationCreate, Entity: newEntity}
+	err := repo.write(tx, change, func(tx *gorm.DB) error {
+		if err := repo.transCheck(tx).Create(&change.Entity).Error; err != nil {
+			return repo.translate(err)
+		}
+		change.ID = change.Entity.GetID()
+		return nil
+	})
+	if err != nil {
 		var zeroValue T
 		return zeroValue, err
 	}
 
-	return newEntity, nil
+	return change.Entity, nil
 }
 
 // Update implements AbstractRepository.
 func (repo *abstractRepositoryImpl[T, K]) Update(tx *gorm.DB, id K, newEntity T) error {
-	entity := createInstance[T]()
+	change := &Change[T, K]{Operation: OperationUpdate, ID: id, Entity: newEntity}
+	return repo.write(tx, change, func(tx *gorm.DB) error {
+		return repo.update(tx, id, &change.Entity)
+	})
+}
 
-	if err := repo.transCheck(tx).
-		Model(entity).
-		Where("id = ?", id).
-		Updates(&newEntity).
-		Error; err != nil {
+// Helper function update writes the entity, checking its version if it is Versioned.
+func (repo *abstractRepositoryImpl[T, K]) update(tx *gorm.DB, id K, newEntity *T) error {
+	entity := createInstance[T]()
+	db, err := repo.byID(repo.transCheck(tx).Model(entity), id)
+	if err != nil {
 		return err
 	}
 
+	versioned, ok := asVersioned(newEntity)
+	if !ok {
+		result := db.Updates(newEntity)
+		if result.Error != nil {
+			return repo.translate(result.Error)
+		}
+		return repo.checkAffected(tx, id, result.RowsAffected, false)
+	}
+
+	// optimistic locking: only the expected version is updated, and the version is incremented
+	expected := versioned.GetVersion()
+	versioned.SetVersion(expected + 1)
+
+	column, _ := repo.versionKeys()
+	result := db.Where(column+" = ?", expected).Updates(newEntity)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = repo.staleOrNotFound(tx, id)
+	}
+	if result.Error != nil {
+		versioned.SetVersion(expected)
+		return repo.translate(result.Error)
+	}
+
 	return nil
 }
 
 func (repo *abstractRepositoryImpl[T, K]) UpdateSpecific(tx *gorm.DB, id K, specificFields map[string]interface{}) error {
-	entity := createInstance[T]()
+	change := &Change[T, K]{Operation: OperationUpdate, ID: id, Fields: maps.Clone(specificFields)}
+	return repo.write(tx, change, func(tx *gorm.DB) error {
+		return repo.updateSpecific(tx, id, change.Fields)
+	})
+}
 
-	if err := repo.transCheck(tx).
-		Model(entity).
-		Where("id = ?", id).
-		Updates(specificFields).
-		Error; err != nil {
+// Helper function updateSpecific writes the fields, incrementing (and checking) the version if T is Versioned.
+func (repo *abstractRepositoryImpl[T, K]) updateSpecific(tx *gorm.DB, id K, specificFields map[string]interface{}) error {
+	entity := createInstance[T]()
+	db, err := repo.byID(repo.transCheck(tx).Model(entity), id)
+	if err != nil {
 		return err
 	}
 
+	if _, ok := asVersioned(entity); !ok {
+		result := db.Updates(specificFields)
+		if result.Error != nil {
+			return repo.translate(result.Error)
+		}
+		return repo.checkAffected(tx, id, result.RowsAffected, false)
+	}
+
+	// optimistic locking: the version is always incremented, and checked if the expected one is given
+	fields := maps.Clone(specificFields)
+	column, name := repo.versionKeys()
+	expected, ok := fields[column]
+	if !ok && name != "" {
+		expected, ok = fields[name]
+	}
+	if ok {
+		db = db.Where(column+" = ?", expected)
+	}
+	delete(fields, name)
+	fields[column] = gorm.Expr(column + " + 1")
+
+	result := db.Updates(fields)
+	if result.Error != nil {
+		return repo.translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repo.staleOrNotFound(tx, id)
+	}
+
 	return nil
 }
 
 // Delete implements AbstractRepository.
 func (repo *abstractRepositoryImpl[T, K]) Delete(tx *gorm.DB, id K) error {
-	entity := createInstance[T]()
-
-	if err := repo.transCheck(tx).
-		Where("id = ?", id).
-		Delete(entity).
-		Error; err != nil {
-		return err
-	}
-	return nil
+	return repo.write(tx, &Change[T, K]{Operation: OperationDelete, ID: id}, func(tx *gorm.DB) error {
+		db, err := repo.byID(repo.transCheck(tx), id)
+		if err != nil {
+			return err
+		}
+
+		result := repo.softDelete(db)
+		if result.Error != nil {
+			return repo.translate(result.Error)
+		}
+		return repo.checkAffected(tx, id, result.RowsAffected, false)
+	})
 }
 
 // Restore implements AbstractRepository.
 func (repo *abstractRepositoryImpl[T, K]) Restore(tx *gorm.DB, id K) error {
-	entity := createInstance[T]()
-
-	result := repo.transCheck(tx).
-		Unscoped().
-		Model(entity).
-		Where("id = ?", id).
-		Update("deleted_at", nil)
-	if result.Error != nil {
-		return result.Error
+	deletedAt, err := repo.deletedAtColumn()
+	if err != nil {
+		return err
 	}
 
-	return nil
+	return repo.write(tx, &Change[T, K]{Operation: OperationRestore, ID: id}, func(tx *gorm.DB) error {
+		entity := createInstance[T]()
+		db, err := repo.byID(repo.transCheck(tx).Unscoped().Model(entity), id)
+		if err != nil {
+			return err
+		}
+
+		result := db.Updates(repo.restoreFields(tx, deletedAt))
+		if result.Error != nil {
+			return repo.translate(result.Error)
+		}
+
+		return repo.checkAffected(tx, id, result.RowsAffected, true)
+	})
 }
 
 func (repo *abstractRepositoryImpl[T, K]) GetPreloads() []string {
@@ -241,13 +514,18 @@ func (repo *abstractRepositoryImpl[T, K]) GetType() string {
 }
 
 func (repo *abstractRepositoryImpl[T, K]) TransactionCheck(tx *gorm.DB) *gorm.DB {
-	db := tx
-	if db == nil {
-		db = repo.gorm
-		return db
+	return repo.transCheck(tx)
+}
+
+// WithContext implements AbstractRepository.
+func (repo *abstractRepositoryImpl[T, K]) WithContext(ctx context.Context) AbstractRepository[T, K] {
+	if ctx == nil {
+		panic("[lib] ctx is nil")
 	}
 
-	return db
+	view := *repo
+	view.ctx = ctx
+	return &view
 }
 
 // Helper function createInstance dynamically creates a new instance of type T.
@@ -265,17 +543,146 @@ func applyPreloads(db *gorm.DB, preloads []string) *gorm.DB {
 }
 
 // Helper function to check if it is within a transactional context to use the
-// transaction or use the current repository
+// transaction or use the current repository, the bound context (if any) is applied to both.
 func (repo *abstractRepositoryImpl[T, K]) transCheck(tx *gorm.DB) *gorm.DB {
 	db := tx
+	if db == nil {
+		db = repo.contextTransaction()
+	}
 	if db == nil {
 		db = repo.gorm
-		return db
 	}
 
+	if repo.ctx != nil {
+		db = db.WithContext(repo.ctx)
+	}
 	return db
 }
 
+// Helper function translate converts the driver errors into the errors of the library, see translateError.
+// The columns of a constraint not reported by the driver are resolved from the schema of T.
+func (repo *abstractRepositoryImpl[T, K]) translate(err error) error {
+	err = translateError(repo.gorm.Dialector.Name(), err)
+
+	var constraintErr *ConstraintError
+	if errors.As(err, &constraintErr) && len(constraintErr.Columns) == 0 {
+		if sch, schemaErr := repo.schema(); schemaErr == nil {
+			constraintErr.Columns = constraintColumns(sch, constraintErr.Constraint)
+		}
+	}
+	return err
+}
+
+// Helper function checkAffected returns ErrRecordNotFound when a write by ID did not affect any row
+// and the entity does not exist. MySQL/MariaDB only report the changed rows, so a write that sets
+// the same values affects none even if the entity exists.
+func (repo *abstractRepositoryImpl[T, K]) checkAffected(tx *gorm.DB, id K, rowsAffected int64, unscoped bool) error {
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	exists, err := repo.existsByID(tx, id, unscoped)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// Helper function existsByID reports whether the entity exists, including the deleted ones if unscoped.
+func (repo *abstractRepositoryImpl[T, K]) existsByID(tx *gorm.DB, id K, unscoped bool) (bool, error) {
+	var count int64
+
+	db := repo.transCheck(tx)
+	if unscoped {
+		db = db.Unscoped()
+	}
+
+	db, err := repo.byID(db.Model(new(T)), id)
+	if err != nil {
+		return false, err
+	}
+
+	if err := db.Count(&count).Error; err != nil {
+		return false, repo.translate(err)
+	}
+	return count > 0, nil
+}
+
+// Helper function contextTransaction returns the transaction carried by the context of the repository, if any.
+func (repo *abstractRepositoryImpl[T, K]) contextTransaction() *gorm.DB {
+	tx, _ := TransactionFromContext(repo.ctx)
+	return tx
+}
+
+// Helper function schema returns the parsed GORM schema of T.
+func (repo *abstractRepositoryImpl[T, K]) schema() (*schema.Schema, error) {
+	stmt := &gorm.Statement{DB: repo.gorm}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, err
+	}
+	return stmt.Schema, nil
+}
+
+// Helper function query prepares a finder query over T with the preloads, the filter and the query options.
+func (repo *abstractRepositoryImpl[T, K]) query(filter Filter, options *queryOptions) (*gorm.DB, error) {
+	sch, err := repo.schema()
+	if err != nil {
+		return nil, err
+	}
+
+	db := repo.transCheck(nil)
+	if !options.withoutDefaultPreloads {
+		db = applyPreloads(db, repo.preloads())
+	}
+
+	db, err = applyPreloadOptions(db, sch, options.preloads)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err = applyFilter(db, sch, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err = repo.scope(db, sch)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err = applySelect(db, sch, options.selects)
+	if err != nil {
+		return nil, err
+	}
+	return applySorts(db, sch, options.sorts)
+}
+
+// Helper function model prepares a query over the model T (without preloads) with the filter,
+// it can optionally be executed within a transaction.
+func (repo *abstractRepositoryImpl[T, K]) model(tx *gorm.DB, filter Filter) (*gorm.DB, error) {
+	sch, err := repo.schema()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := repo.scope(repo.transCheck(tx).Model(new(T)), sch)
+	if err != nil {
+		return nil, err
+	}
+	return applyFilter(db, sch, filter)
+}
+
+// Helper function preloads returns the preloads of the concrete repository if there is one.
+func (repo *abstractRepositoryImpl[T, K]) preloads() []string {
+	if repo.self == nil {
+		return repo.GetPreloads()
+	}
+	return repo.self.GetPreloads()
+}
+
 // CreateRepository initializes a new instance of `abstractRepositoryImpl`
 // with the provided GORM database instance and a self-reference.
 //
@@ -291,6 +698,9 @@ func (repo *abstractRepositoryImpl[T, K]) transCheck(tx *gorm.DB) *gorm.DB {
 //     This must not be nil, otherwise the function will panic.
 //   - self (AbstractRepository[T, K]): A reference to the specific repository implementation.
 //     This is used to allow method overrides or extensions by the concrete repository.
+//     If it implements BeforeWriteHook or AfterWriteHook, its hooks run around every write.
+//   - opts (...RepositoryOption[T, K]): Optional settings of the repository, such as the write hooks
+//     registered with BeforeWrite and AfterWrite.
 //
 // Returns:
 //   - *abstractRepositoryImpl[T, K]: A pointer to the newly created repository instance.
@@ -315,7 +725,7 @@ func (repo *abstractRepositoryImpl[T, K]) transCheck(tx *gorm.DB) *gorm.DB {
 //
 // The `self` parameter ensures that methods defined in the concrete repository (`AccountRepository`)
 // are correctly referenced, enabling method overriding if needed.
-func CreateRepository[T Identifiable[K], K ID](gormDB *gorm.DB, self AbstractRepository[T, K]) *abstractRepositoryImpl[T, K] {
+func CreateRepository[T Identifiable[K], K ID](gormDB *gorm.DB, self AbstractRepository[T, K], opts ...RepositoryOption[T, K]) *abstractRepositoryImpl[T, K] {
 	if gormDB == nil {
 		panic("[lib] gormDB is nil")
 	}
@@ -327,5 +737,17 @@ func CreateRepository[T Identifiable[K], K ID](gormDB *gorm.DB, self AbstractRep
 		gorm: gormDB,
 		self: self,
 	}
+
+	if hook, ok := self.(BeforeWriteHook[T, K]); ok {
+		repo.hooks.before = append(repo.hooks.before, hook.BeforeWrite)
+	}
+	if hook, ok := self.(AfterWriteHook[T, K]); ok {
+		repo.hooks.after = append(repo.hooks.after, hook.AfterWrite)
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(repo)
+		}
+	}
 	return repo
 }
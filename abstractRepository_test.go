@@ -0,0 +1,132 @@
+package stdlib
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type testAccount struct {
+	ID        uint `gorm:"primaryKey"`
+	Username  string
+	Email     string
+	CreatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func (a *testAccount) GetID() uint {
+	return a.ID
+}
+
+type testAccountRepository struct {
+	AbstractRepository[*testAccount, uint]
+}
+
+func newTestAccountRepository(gormDB *gorm.DB) *testAccountRepository {
+	repo := &testAccountRepository{}
+	repo.AbstractRepository = CreateRepository(gormDB, repo)
+	return repo
+}
+
+// Helper: statements captured from a dry run connection
+type capturedStatements struct {
+	sql      []string
+	contexts []context.Context
+}
+
+func (c *capturedStatements) last() string {
+	if len(c.sql) == 0 {
+		return ""
+	}
+	return c.sql[len(c.sql)-1]
+}
+
+// Helper: open a postgres connection in dry run mode, capturing every generated statement
+func newDryRunDB(t *testing.T) (*gorm.DB, *capturedStatements) {
+	return newDryRunDialectorDB(t, postgres.New(postgres.Config{DSN: "host=localhost user=test dbname=test"}))
+}
+
+// Helper: open a mysql connection in dry run mode, capturing every generated statement
+func newDryRunMySQLDB(t *testing.T) (*gorm.DB, *capturedStatements) {
+	return newDryRunDialectorDB(t, mysql.New(mysql.Config{DSN: "test:test@tcp(localhost:3306)/test", SkipInitializeWithVersion: true}))
+}
+
+func newDryRunDialectorDB(t *testing.T, dialector gorm.Dialector) (*gorm.DB, *capturedStatements) {
+	db, err := gorm.Open(dialector, &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to open dry run connection: %v", err)
+	}
+
+	captured := &capturedStatements{}
+	capture := func(db *gorm.DB) {
+		captured.sql = append(captured.sql, db.Dialector.Explain(db.Statement.SQL.String(), db.Statement.Vars...))
+		captured.contexts = append(captured.contexts, db.Statement.Context)
+	}
+
+	_ = db.Callback().Query().After("gorm:query").Register("test:capture", capture)
+	_ = db.Callback().Create().After("gorm:create").Register("test:capture", capture)
+	_ = db.Callback().Update().After("gorm:update").Register("test:capture", capture)
+	_ = db.Callback().Delete().After("gorm:delete").Register("test:capture", capture)
+	_ = db.Callback().Row().After("gorm:row").Register("test:capture", capture)
+	_ = db.Callback().Raw().After("gorm:raw").Register("test:capture", capture)
+
+	return db, captured
+}
+
+type ctxKey struct{}
+
+func TestWithContextPropagatesToQueries(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := newTestAccountRepository(db)
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request")
+
+	_, _ = repo.WithContext(ctx).FindByID(1)
+	assert.Equal(t, "request", captured.contexts[0].Value(ctxKey{}), "Reads should run with the bound context")
+
+	_ = repo.WithContext(ctx).Delete(db.Session(&gorm.Session{}), 1)
+	assert.Equal(t, "request", captured.contexts[1].Value(ctxKey{}), "Writes on a given tx should run with the bound context")
+
+	_, _ = repo.FindByID(1)
+	assert.Nil(t, captured.contexts[len(captured.contexts)-1].Value(ctxKey{}), "The original repository should not be bound to the context")
+}
+
+func TestWithContextNilPanics(t *testing.T) {
+	db, _ := newDryRunDB(t)
+	repo := newTestAccountRepository(db)
+
+	assert.Panics(t, func() {
+		repo.WithContext(nil)
+	})
+}
+
+func TestWritesReportNotFound(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := newTestAccountRepository(db)
+
+	// a dry run never affects rows nor finds them, so every write reports the entity as missing
+	assert.ErrorIs(t, repo.Update(nil, 1, &testAccount{Username: "john"}), ErrRecordNotFound)
+	assert.ErrorIs(t, repo.UpdateSpecific(nil, 1, map[string]interface{}{"username": "john"}), ErrRecordNotFound)
+	assert.ErrorIs(t, repo.Delete(nil, 1), ErrRecordNotFound)
+	assert.ErrorIs(t, repo.Restore(nil, 1), ErrRecordNotFound)
+
+	assert.Contains(t, captured.last(), `SELECT count(*) FROM "test_accounts" WHERE "test_accounts"."id" = 1`)
+	assert.NotContains(t, captured.last(), "deleted_at", "Restore should look for deleted entities too")
+}
+
+func TestFindAllEmptyTable(t *testing.T) {
+	db, _ := newDryRunDB(t)
+	repo := newTestAccountRepository(db)
+
+	accounts, err := repo.FindAll()
+	assert.NoError(t, err, "An empty table should not be an error")
+	assert.Empty(t, accounts)
+}
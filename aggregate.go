@@ -0,0 +1,155 @@
+package stdlib
+
+import (
+	"fmt"
+
+	"gorm.io/gorm/clause"
+)
+
+// AggregateFunction is a SQL aggregate function computed by Aggregate.
+type AggregateFunction string
+
+const (
+	AggregateCount AggregateFunction = "COUNT"
+	AggregateSum   AggregateFunction = "SUM"
+	AggregateMin   AggregateFunction = "MIN"
+	AggregateMax   AggregateFunction = "MAX"
+	AggregateAvg   AggregateFunction = "AVG"
+)
+
+// Number is the constraint of the values that can be summed with Sum.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// Aggregator is implemented by every AbstractRepository, it is what the typed aggregate helpers need.
+type Aggregator interface {
+	Aggregate(dest any, function AggregateFunction, column, groupBy string, filter Filter) error
+}
+
+type aggregateResult[R any] struct {
+	Result *R
+}
+
+type aggregateGroup[V, R any] struct {
+	GroupValue V
+	Result     R
+}
+
+// Sum returns the sum of the column for the entities matching the filter, 0 if none matches.
+//
+//	total, err := stdlib.Sum[int64](repo, "amount", stdlib.Eq("status", "paid"))
+func Sum[N Number](repo Aggregator, column string, filter Filter) (N, error) {
+	var sum aggregateResult[N]
+	if err := repo.Aggregate(&sum, AggregateSum, column, "", filter); err != nil || sum.Result == nil {
+		return 0, err
+	}
+	return *sum.Result, nil
+}
+
+// Avg returns the average of the column for the entities matching the filter, 0 if none matches.
+func Avg(repo Aggregator, column string, filter Filter) (float64, error) {
+	var avg aggregateResult[float64]
+	if err := repo.Aggregate(&avg, AggregateAvg, column, "", filter); err != nil || avg.Result == nil {
+		return 0, err
+	}
+	return *avg.Result, nil
+}
+
+// Min returns the smallest value of the column for the entities matching the filter.
+// Returns ErrRecordNotFound if no entity matches.
+func Min[V any](repo Aggregator, column string, filter Filter) (V, error) {
+	return extremum[V](repo, AggregateMin, column, filter)
+}
+
+// Max returns the greatest value of the column for the entities matching the filter.
+// Returns ErrRecordNotFound if no entity matches.
+func Max[V any](repo Aggregator, column string, filter Filter) (V, error) {
+	return extremum[V](repo, AggregateMax, column, filter)
+}
+
+// CountGroupBy counts the entities matching the filter per distinct value of the column.
+//
+//	perStatus, err := stdlib.CountGroupBy[string](repo, "status", nil)
+func CountGroupBy[V comparable](repo Aggregator, column string, filter Filter) (map[V]int64, error) {
+	var groups []aggregateGroup[V, int64]
+	if err := repo.Aggregate(&groups, AggregateCount, "", column, filter); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[V]int64, len(groups))
+	for _, group := range groups {
+		counts[group.GroupValue] = group.Result
+	}
+	return counts, nil
+}
+
+// Helper function extremum computes MIN or MAX, a NULL result means no entity matched.
+func extremum[V any](repo Aggregator, function AggregateFunction, column string, filter Filter) (V, error) {
+	var value aggregateResult[V]
+	if err := repo.Aggregate(&value, function, column, "", filter); err != nil {
+		var zeroValue V
+		return zeroValue, err
+	}
+	if value.Result == nil {
+		var zeroValue V
+		return zeroValue, ErrRecordNotFound
+	}
+	return *value.Result, nil
+}
+
+// Count implements AbstractRepository.
+func (repo *abstractRepositoryImpl[T, K]) Count() (int64, error) {
+	return repo.CountWhere(nil)
+}
+
+// ExistsByID implements AbstractRepository.
+func (repo *abstractRepositoryImpl[T, K]) ExistsByID(id K) (bool, error) {
+	return repo.existsByID(nil, id, false)
+}
+
+// Aggregate implements AbstractRepository.
+func (repo *abstractRepositoryImpl[T, K]) Aggregate(dest any, function AggregateFunction, column, groupBy string, filter Filter) error {
+	sch, err := repo.schema()
+	if err != nil {
+		return err
+	}
+
+	var argument any = clause.Expr{SQL: "*"}
+	switch function {
+	case AggregateCount, AggregateSum, AggregateMin, AggregateMax, AggregateAvg:
+	default:
+		return fmt.Errorf("unsupported aggregate function %q", function)
+	}
+	if column != "" || function != AggregateCount {
+		name, err := resolveColumn(sch, column)
+		if err != nil {
+			return err
+		}
+		argument = clause.Column{Table: clause.CurrentTable, Name: name}
+	}
+
+	db, err := repo.model(nil, filter)
+	if err != nil {
+		return err
+	}
+
+	if groupBy == "" {
+		db = db.Select(string(function)+"(?) AS result", argument)
+	} else {
+		name, err := resolveColumn(sch, groupBy)
+		if err != nil {
+			return err
+		}
+		group := clause.Column{Table: clause.CurrentTable, Name: name}
+		db = db.Select("? AS group_value, "+string(function)+"(?) AS result", group, argument).
+			Clauses(clause.GroupBy{Columns: []clause.Column{group}})
+	}
+
+	if err := db.Find(dest).Error; err != nil {
+		return repo.translate(err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package stdlib
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregateHelpers(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := newTestAccountRepository(db)
+
+	sum, err := Sum[int64](repo, "id", Like("email", "%@newcore.gg"))
+	assert.NoError(t, err)
+	assert.Zero(t, sum, "No matching entity should sum to zero")
+	assert.Contains(t, captured.last(), `SELECT SUM("test_accounts"."id") AS result FROM "test_accounts" WHERE "test_accounts"."email" LIKE '%@newcore.gg' AND "test_accounts"."deleted_at" IS NULL`)
+
+	avg, err := Avg(repo, "ID", nil)
+	assert.NoError(t, err)
+	assert.Zero(t, avg)
+	assert.Contains(t, captured.last(), `SELECT AVG("test_accounts"."id") AS result FROM "test_accounts"`)
+
+	_, err = Max[time.Time](repo, "created_at", nil)
+	assert.ErrorIs(t, err, ErrRecordNotFound, "No matching entity has no maximum")
+	assert.Contains(t, captured.last(), `SELECT MAX("test_accounts"."created_at") AS result`)
+
+	counts, err := CountGroupBy[string](repo, "username", nil)
+	assert.NoError(t, err)
+	assert.Empty(t, counts)
+	assert.Contains(t, captured.last(), `SELECT "test_accounts"."username" AS group_value, COUNT(*) AS result FROM "test_accounts" WHERE "test_accounts"."deleted_at" IS NULL GROUP BY "test_accounts"."username"`)
+}
+
+func TestAggregateRejectsUnknownColumns(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := newTestAccountRepository(db)
+
+	_, err := Min[int](repo, "password", nil)
+	assert.ErrorIs(t, err, ErrUnknownColumn)
+
+	_, err = CountGroupBy[string](repo, "1; DROP TABLE test_accounts", nil)
+	assert.ErrorIs(t, err, ErrUnknownColumn)
+
+	var result aggregateResult[int64]
+	assert.Error(t, repo.Aggregate(&result, "MEDIAN", "id", "", nil))
+	assert.Empty(t, captured.sql, "Invalid aggregates should not reach the database")
+}
+
+func TestCountAndExistsByID(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := newTestAccountRepository(db)
+
+	_, err := repo.Count()
+	assert.NoError(t, err)
+	assert.Contains(t, captured.last(), `SELECT count(*) FROM "test_accounts" WHERE "test_accounts"."deleted_at" IS NULL`)
+
+	exists, err := repo.ExistsByID(7)
+	assert.NoError(t, err)
+	assert.False(t, exists)
+	assert.Contains(t, captured.last(), `WHERE "test_accounts"."id" = 7 AND "test_accounts"."deleted_at" IS NULL`)
+}
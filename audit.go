@@ -0,0 +1,218 @@
+package stdlib
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"gorm.io/gorm"
+)
+
+type actorKey struct{}
+
+// AuditEntry is a write recorded by a repository created with the WithAudit option.
+// The audit_entries table must be migrated by the application (e.g. AutoMigrate(&stdlib.AuditEntry{})).
+type AuditEntry struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	EntityType string    `gorm:"size:255;index:idx_audit_entries_entity" json:"entityType"`
+	EntityID   string    `gorm:"size:255;index:idx_audit_entries_entity" json:"entityId"`
+	Operation  Operation `gorm:"size:16" json:"operation"`
+	Actor      string    `gorm:"size:255" json:"actor"`
+	Diff       string    `gorm:"type:text" json:"diff"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// AuditChange is the value of a field before and after a write, Diff is a JSON object of them by field.
+type AuditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// WithActor returns a copy of ctx carrying the actor (e.g. the ID of the authenticated user)
+// recorded by the audit trail. Bind it to the repository with WithContext.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor of the context set with WithActor, if any.
+func ActorFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	actor, ok := ctx.Value(actorKey{}).(string)
+	return actor, ok
+}
+
+// WithAudit records an AuditEntry for every Create, Update, UpdateSpecific, Delete, HardDelete and Restore
+// of the repository, in the same transaction as the write (see BeforeWrite). HardDelete is recorded as an
+// OperationHardDelete, so the permanent deletes can be told from the soft ones.
+// The entry holds the entity type (GetType), its ID, the actor of the context (see WithActor) and the fields
+// that changed with their previous and new values.
+//
+// The writes that do not run the hooks are not recorded: the bulk writes (CreateMany, UpdateMany, DeleteMany,
+// DeleteByIDs, RestoreByIDs), PurgeDeletedBefore, Upsert and UpsertMany.
+//
+//	repo.AbstractRepository = stdlib.CreateRepository(gormDB, repo, stdlib.WithAudit[*models.Account, uint]())
+//	...
+//	err := repo.WithContext(stdlib.WithActor(ctx, userID)).Delete(nil, id)
+//	history, err := repo.History(id)
+func WithAudit[T Identifiable[K], K ID]() RepositoryOption[T, K] {
+	return func(repo *abstractRepositoryImpl[T, K]) {
+		repo.hooks.before = append(repo.hooks.before, repo.auditBefore)
+		repo.hooks.after = append(repo.hooks.after, repo.auditAfter)
+	}
+}
+
+// History implements AbstractRepository.
+func (repo *abstractRepositoryImpl[T, K]) History(id K) ([]AuditEntry, error) {
+	var entries []AuditEntry
+
+	entityID, err := auditEntityID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// a tenant scoped repository only returns the history of the entities of the tenant
+	if _, scoped, err := repo.tenant(repo.transCheck(nil)); err != nil {
+		return nil, err
+	} else if scoped {
+		exists, err := repo.existsByID(nil, id, true)
+		if err != nil || !exists {
+			return []AuditEntry{}, err
+		}
+	}
+
+	err = repo.transCheck(nil).
+		Where(&AuditEntry{EntityType: repo.entityType(), EntityID: entityID}).
+		Order("created_at, id").
+		Find(&entries).Error
+	if err != nil {
+		return entries, repo.translate(err)
+	}
+	return entries, nil
+}
+
+// Helper function auditBefore takes the snapshot of the entity before the write.
+func (repo *abstractRepositoryImpl[T, K]) auditBefore(tx *gorm.DB, change *Change[T, K]) error {
+	if change.Operation == OperationCreate {
+		return nil
+	}
+
+	snapshot, err := repo.auditSnapshot(tx, change.ID)
+	if err != nil {
+		return err
+	}
+	change.auditBefore = snapshot
+	return nil
+}
+
+// Helper function auditAfter records the audit entry with the changes between the snapshots.
+func (repo *abstractRepositoryImpl[T, K]) auditAfter(tx *gorm.DB, change *Change[T, K]) error {
+	var after map[string]any
+	var err error
+	if change.Operation == OperationCreate {
+		after, err = auditFields(change.Entity)
+	} else {
+		after, err = repo.auditSnapshot(tx, change.ID)
+	}
+	if err != nil {
+		return err
+	}
+
+	diff, err := sonic.Marshal(auditDiff(change.auditBefore, after))
+	if err != nil {
+		return err
+	}
+	entityID, err := auditEntityID(change.ID)
+	if err != nil {
+		return err
+	}
+
+	actor, _ := ActorFromContext(tx.Statement.Context)
+	entry := &AuditEntry{
+		EntityType: repo.entityType(),
+		EntityID:   entityID,
+		Operation:  change.Operation,
+		Actor:      actor,
+		Diff:       string(diff),
+	}
+	if err := tx.Session(&gorm.Session{NewDB: true}).Create(entry).Error; err != nil {
+		return repo.translate(err)
+	}
+	return nil
+}
+
+// Helper function auditSnapshot loads the fields of the entity, deleted or not, nil if there is none.
+func (repo *abstractRepositoryImpl[T, K]) auditSnapshot(tx *gorm.DB, id K) (map[string]any, error) {
+	var entity T
+
+	db, err := repo.byID(tx.Session(&gorm.Session{NewDB: true}).Unscoped(), id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := db.Limit(1).Find(&entity)
+	if result.Error != nil {
+		return nil, repo.translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return auditFields(entity)
+}
+
+// Helper function entityType returns the type of the concrete repository if there is one.
+func (repo *abstractRepositoryImpl[T, K]) entityType() string {
+	if repo.self == nil {
+		return repo.GetType()
+	}
+	return repo.self.GetType()
+}
+
+// Helper function auditFields returns the fields of the entity as they are encoded in JSON.
+func auditFields(entity any) (map[string]any, error) {
+	data, err := sonic.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]any
+	if err := sonic.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// Helper function auditDiff returns the fields whose value differs between the snapshots.
+func auditDiff(before, after map[string]any) map[string]AuditChange {
+	diff := make(map[string]AuditChange)
+	for field, value := range after {
+		if previous, ok := before[field]; !ok || !reflect.DeepEqual(previous, value) {
+			diff[field] = AuditChange{Before: previous, After: value}
+		}
+	}
+	for field, previous := range before {
+		if _, ok := after[field]; !ok {
+			diff[field] = AuditChange{Before: previous}
+		}
+	}
+	return diff
+}
+
+// Helper function auditEntityID formats the ID of an entity, composite keys are encoded in JSON.
+func auditEntityID(id any) (string, error) {
+	if stringer, ok := id.(fmt.Stringer); ok {
+		return stringer.String(), nil
+	}
+	if reflect.TypeOf(id).Kind() != reflect.Struct {
+		return fmt.Sprint(id), nil
+	}
+
+	data, err := sonic.Marshal(id)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,74 @@
+package stdlib
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func newTestAuditedAccountRepository(gormDB *gorm.DB) *testAccountRepository {
+	repo := &testAccountRepository{}
+	repo.AbstractRepository = CreateRepository(gormDB, repo, WithAudit[*testAccount, uint]())
+	return repo
+}
+
+func TestActorContext(t *testing.T) {
+	_, ok := ActorFromContext(context.Background())
+	assert.False(t, ok)
+
+	actor, ok := ActorFromContext(WithActor(context.Background(), "admin"))
+	assert.True(t, ok)
+	assert.Equal(t, "admin", actor)
+}
+
+func TestAuditRecordsWrites(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := newTestAuditedAccountRepository(db)
+	ctx := WithActor(context.Background(), "admin")
+
+	_, err := repo.WithContext(ctx).Create(db.Session(&gorm.Session{}), &testAccount{Username: "john"})
+	assert.NoError(t, err)
+	assert.Len(t, captured.sql, 2, "Create should not load a previous snapshot")
+	assert.Contains(t, captured.last(), `INSERT INTO "audit_entries" ("entity_type","entity_id","operation","actor","diff","created_at") VALUES ('abstractRepositoryImpl[T: *stdlib.testAccount, K: uint]','0','create','admin',`)
+	assert.Contains(t, captured.last(), `"Username":{"before":null,"after":"john"}`)
+	assert.Equal(t, "admin", captured.contexts[len(captured.contexts)-1].Value(actorKey{}), "The audit entry should be written with the context of the write")
+
+	captured.sql = nil
+	assert.ErrorIs(t, repo.Delete(db.Session(&gorm.Session{}), 1), ErrRecordNotFound)
+	assert.Contains(t, captured.sql[0], `SELECT * FROM "test_accounts" WHERE "test_accounts"."id" = 1 LIMIT 1`, "The snapshot should include deleted entities")
+	assert.NotContains(t, captured.last(), "audit_entries", "A failed write should not be audited")
+
+	// the hard delete reports an affected row, so it is audited
+	_ = db.Callback().Delete().After("gorm:delete").Register("test:affected", func(db *gorm.DB) {
+		db.RowsAffected = 1
+	})
+	captured.sql = nil
+	assert.NoError(t, repo.WithContext(ctx).HardDelete(db.Session(&gorm.Session{}), 1))
+	assert.Contains(t, captured.sql[0], `SELECT * FROM "test_accounts" WHERE "test_accounts"."id" = 1 LIMIT 1`, "A hard delete should be audited too")
+	assert.Contains(t, captured.sql[1], `DELETE FROM "test_accounts" WHERE "test_accounts"."id" = 1`)
+	assert.Contains(t, captured.last(), `'abstractRepositoryImpl[T: *stdlib.testAccount, K: uint]','1','hard_delete','admin',`)
+}
+
+func TestAuditDiff(t *testing.T) {
+	diff := auditDiff(
+		map[string]any{"Username": "john", "Email": "john@newcore.gg"},
+		map[string]any{"Username": "john", "Email": "doe@newcore.gg"},
+	)
+	assert.Equal(t, map[string]AuditChange{"Email": {Before: "john@newcore.gg", After: "doe@newcore.gg"}}, diff)
+
+	id, err := auditEntityID(testMembershipKey{AccountID: 1, GroupID: 2})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"AccountID":1,"GroupID":2}`, id)
+}
+
+func TestHistory(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := newTestAuditedAccountRepository(db)
+
+	entries, err := repo.History(7)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+	assert.Equal(t, `SELECT * FROM "audit_entries" WHERE "audit_entries"."entity_type" = 'abstractRepositoryImpl[T: *stdlib.testAccount, K: uint]' AND "audit_entries"."entity_id" = '7' ORDER BY created_at, id`, captured.last())
+}
@@ -0,0 +1,134 @@
+package stdlib
+
+import (
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+// Fields storing the actor of the writes of the Auditable entities.
+const (
+	createdByField string = "CreatedBy"
+	updatedByField string = "UpdatedBy"
+	deletedByField string = "DeletedBy"
+)
+
+// Auditable is implemented by the entities recording who wrote them. When the context of the repository
+// carries an actor (see WithActor), Create sets CreatedBy and UpdatedBy, Update sets UpdatedBy, and
+// UpdateSpecific, UpdateMany and Restore write the actor in the column of the UpdatedBy field. The soft deletes
+// write it in the column of the DeletedBy field, which Restore clears. The columns are skipped if T has no such field.
+//
+//	type Account struct {
+//		ID        uint
+//		CreatedBy string
+//		UpdatedBy string
+//		DeletedBy string
+//		DeletedAt gorm.DeletedAt
+//	}
+//
+//	func (a *Account) SetCreatedBy(actor string) { a.CreatedBy = actor }
+//	func (a *Account) SetUpdatedBy(actor string) { a.UpdatedBy = actor }
+//	func (a *Account) SetDeletedBy(actor string) { a.DeletedBy = actor }
+type Auditable interface {
+	SetCreatedBy(actor string)
+	SetUpdatedBy(actor string)
+	SetDeletedBy(actor string)
+}
+
+// Helper function asAuditable returns the entity as Auditable, either by value or by pointer.
+func asAuditable[T any](entity *T) (Auditable, bool) {
+	if auditable, ok := any(*entity).(Auditable); ok {
+		return auditable, true
+	}
+	auditable, ok := any(entity).(Auditable)
+	return auditable, ok
+}
+
+// Helper function auditActor returns the actor of the context of the query when T is Auditable.
+func (repo *abstractRepositoryImpl[T, K]) auditActor(db *gorm.DB) (string, bool) {
+	if _, ok := asAuditable(new(T)); !ok {
+		return "", false
+	}
+	return ActorFromContext(db.Statement.Context)
+}
+
+// Helper function stampActor sets the actor of the context of the query on the entities,
+// as creator too when they are created.
+func (repo *abstractRepositoryImpl[T, K]) stampActor(db *gorm.DB, created bool, entities ...*T) {
+	actor, ok := repo.auditActor(db)
+	if !ok {
+		return
+	}
+
+	for _, entity := range entities {
+		if value := reflect.ValueOf(*entity); value.Kind() == reflect.Pointer && value.IsNil() {
+			continue
+		}
+		auditable, _ := asAuditable(entity)
+		if created {
+			auditable.SetCreatedBy(actor)
+		}
+		auditable.SetUpdatedBy(actor)
+	}
+}
+
+// Helper function stampActors sets the actor of the context of the query on the created entities of the slice.
+func (repo *abstractRepositoryImpl[T, K]) stampActors(db *gorm.DB, entities []T) {
+	for i := range entities {
+		repo.stampActor(db, true, &entities[i])
+	}
+}
+
+// Helper function stampActorField adds the actor of the context of the query to the updated fields.
+func (repo *abstractRepositoryImpl[T, K]) stampActorField(db *gorm.DB, fields map[string]interface{}) {
+	if actor, ok := repo.auditActor(db); ok {
+		if updatedBy := repo.auditableColumn(updatedByField); updatedBy != "" {
+			fields[updatedBy] = actor
+		}
+	}
+}
+
+// Helper function softDelete deletes the entities matched by the query, recording the actor of the context
+// in the column of the DeletedBy field when T is Auditable and supports soft delete. As the soft delete of gorm,
+// it only writes the deletion columns: no hook runs and the update time is not changed.
+func (repo *abstractRepositoryImpl[T, K]) softDelete(db *gorm.DB) *gorm.DB {
+	actor, ok := repo.auditActor(db)
+	deletedBy := repo.auditableColumn(deletedByField)
+	if !ok || deletedBy == "" {
+		return db.Delete(new(T))
+	}
+	deletedAt, err := repo.deletedAtColumn()
+	if err != nil {
+		return db.Delete(new(T))
+	}
+
+	return db.Model(new(T)).UpdateColumns(map[string]interface{}{
+		deletedAt: db.NowFunc(),
+		deletedBy: actor,
+	})
+}
+
+// Helper function restoreFields returns the fields written by a restore, clearing the column of the DeletedBy field
+// and recording the actor of the context when T is Auditable.
+func (repo *abstractRepositoryImpl[T, K]) restoreFields(db *gorm.DB, deletedAt string) map[string]interface{} {
+	fields := map[string]interface{}{deletedAt: nil}
+	if _, ok := repo.auditActor(db); ok {
+		if deletedBy := repo.auditableColumn(deletedByField); deletedBy != "" {
+			fields[deletedBy] = ""
+		}
+		repo.stampActorField(db, fields)
+	}
+	return fields
+}
+
+// Helper function auditableColumn returns the column of the field of T storing an actor, empty if T has none.
+func (repo *abstractRepositoryImpl[T, K]) auditableColumn(name string) string {
+	sch, err := repo.schema()
+	if err != nil {
+		return ""
+	}
+	if field := sch.LookUpField(name); field != nil {
+		return field.DBName
+	}
+	return ""
+}
@@ -0,0 +1,118 @@
+package stdlib
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testPost struct {
+	ID        uint `gorm:"primaryKey"`
+	Title     string
+	CreatedBy string
+	UpdatedBy string
+	DeletedBy string
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt
+}
+
+func (p *testPost) GetID() uint               { return p.ID }
+func (p *testPost) SetCreatedBy(actor string) { p.CreatedBy = actor }
+func (p *testPost) SetUpdatedBy(actor string) { p.UpdatedBy = actor }
+func (p *testPost) SetDeletedBy(actor string) { p.DeletedBy = actor }
+
+type testPostRepository struct {
+	AbstractRepository[*testPost, uint]
+}
+
+func newTestPostRepository(gormDB *gorm.DB) *testPostRepository {
+	repo := &testPostRepository{}
+	repo.AbstractRepository = CreateRepository(gormDB, repo)
+	return repo
+}
+
+func TestAuditableStampsActor(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := newTestPostRepository(db).WithContext(WithActor(context.Background(), "admin"))
+
+	created, err := repo.Create(nil, &testPost{Title: "hello"})
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", created.CreatedBy)
+	assert.Equal(t, "admin", created.UpdatedBy)
+
+	assert.ErrorIs(t, repo.UpdateSpecific(nil, 1, map[string]interface{}{"title": "bye"}), ErrRecordNotFound)
+	assert.Contains(t, captured.sql[len(captured.sql)-2], `UPDATE "test_posts" SET "title"='bye',"updated_by"='admin'`)
+
+	assert.ErrorIs(t, repo.Delete(nil, 1), ErrRecordNotFound)
+	assert.Contains(t, captured.sql[len(captured.sql)-2], `"deleted_by"='admin' WHERE "test_posts"."id" = 1 AND "test_posts"."deleted_at" IS NULL`)
+	assert.NotContains(t, captured.sql[len(captured.sql)-2], "updated_at", "The soft delete should not change the update time")
+
+	assert.ErrorIs(t, repo.Restore(nil, 1), ErrRecordNotFound)
+	assert.Contains(t, captured.sql[len(captured.sql)-2], `UPDATE "test_posts" SET "deleted_at"=NULL,"deleted_by"='',"updated_by"='admin',"updated_at"=`)
+}
+
+type testNote struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedBy string
+	UpdatedBy string `gorm:"column:modified_by"`
+	DeletedAt gorm.DeletedAt
+}
+
+func (n *testNote) GetID() uint               { return n.ID }
+func (n *testNote) SetCreatedBy(actor string) { n.CreatedBy = actor }
+func (n *testNote) SetUpdatedBy(actor string) { n.UpdatedBy = actor }
+func (n *testNote) SetDeletedBy(string)       {}
+
+type testNoteRepository struct {
+	AbstractRepository[*testNote, uint]
+}
+
+func TestAuditableColumnsFromSchema(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	notes := &testNoteRepository{}
+	notes.AbstractRepository = CreateRepository(db, notes)
+	repo := notes.WithContext(WithActor(context.Background(), "admin"))
+
+	_, err := repo.UpdateMany(nil, Eq("id", 1), map[string]interface{}{"created_by": "john"})
+	assert.NoError(t, err)
+	assert.Contains(t, captured.last(), `UPDATE "test_notes" SET "created_by"='john',"modified_by"='admin'`)
+
+	_, err = repo.DeleteByIDs(nil, []uint{1})
+	assert.NoError(t, err)
+	assert.Contains(t, captured.last(), `UPDATE "test_notes" SET "deleted_at"=`, "Without a DeletedBy field the soft delete should be the one of gorm")
+	assert.NotContains(t, captured.last(), "deleted_by")
+
+	_, err = repo.RestoreByIDs(nil, []uint{1})
+	assert.NoError(t, err)
+	assert.Contains(t, captured.last(), `UPDATE "test_notes" SET "deleted_at"=NULL,"modified_by"='admin'`)
+}
+
+func TestAuditableUpsertKeepsCreator(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := newTestPostRepository(db).WithContext(WithActor(context.Background(), "admin"))
+
+	_, err := repo.Upsert(nil, &testPost{ID: 1, Title: "hello"}, UpsertOptions{})
+	assert.NoError(t, err)
+	assert.Contains(t, captured.last(), `ON CONFLICT ("id") DO UPDATE SET "title"="excluded"."title","updated_by"="excluded"."updated_by","deleted_by"="excluded"."deleted_by","updated_at"="excluded"."updated_at","deleted_at"="excluded"."deleted_at"`)
+
+	_, err = repo.UpsertMany(nil, []*testPost{{ID: 1, Title: "hello"}}, UpsertOptions{UpdateColumns: []string{"title", "created_by"}})
+	assert.NoError(t, err)
+	assert.Contains(t, captured.last(), `ON CONFLICT ("id") DO UPDATE SET "title"="excluded"."title"`)
+	assert.NotContains(t, captured.last(), `"created_by"="excluded"`, "The creator should never be overwritten")
+}
+
+func TestAuditableWithoutActor(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := newTestPostRepository(db)
+
+	created, err := repo.Create(nil, &testPost{Title: "hello"})
+	assert.NoError(t, err)
+	assert.Empty(t, created.CreatedBy)
+
+	_, err = repo.DeleteByIDs(nil, []uint{1})
+	assert.NoError(t, err)
+	assert.NotContains(t, captured.last(), "deleted_by", "Without an actor the soft delete should be the one of gorm")
+}
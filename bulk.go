@@ -0,0 +1,111 @@
+package stdlib
+
+import (
+	"maps"
+
+	"gorm.io/gorm"
+)
+
+// DefaultBatchSize is the batch size used by CreateMany when a non positive size is requested.
+const DefaultBatchSize int = 100
+
+// CreateMany implements AbstractRepository.
+func (repo *abstractRepositoryImpl[T, K]) CreateMany(tx *gorm.DB, newEntities []T, batchSize int) ([]T, error) {
+	if len(newEntities) == 0 {
+		return newEntities, nil
+	}
+	if batchSize < 1 {
+		batchSize = DefaultBatchSize
+	}
+
+	db := repo.transCheck(tx)
+	if err := repo.stampTenants(db, newEntities); err != nil {
+		return nil, err
+	}
+	repo.stampActors(db, newEntities)
+
+	if err := db.CreateInBatches(&newEntities, batchSize).Error; err != nil {
+		return nil, repo.translate(err)
+	}
+
+	return newEntities, nil
+}
+
+// UpdateMany implements AbstractRepository.
+func (repo *abstractRepositoryImpl[T, K]) UpdateMany(tx *gorm.DB, filter Filter, specificFields map[string]interface{}) (int64, error) {
+	db, err := repo.model(tx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	fields := maps.Clone(specificFields)
+	if err := repo.withoutTenantField(db, fields); err != nil {
+		return 0, err
+	}
+	repo.stampActorField(db, fields)
+
+	result := db.Updates(fields)
+	if result.Error != nil {
+		return 0, repo.translate(result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// DeleteMany implements AbstractRepository.
+func (repo *abstractRepositoryImpl[T, K]) DeleteMany(tx *gorm.DB, filter Filter) (int64, error) {
+	db, err := repo.model(tx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	result := repo.softDelete(db)
+	if result.Error != nil {
+		return 0, repo.translate(result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// DeleteByIDs implements AbstractRepository.
+func (repo *abstractRepositoryImpl[T, K]) DeleteByIDs(tx *gorm.DB, ids []K) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	db, err := repo.byIDs(repo.transCheck(tx), ids)
+	if err != nil {
+		return 0, err
+	}
+
+	result := repo.softDelete(db)
+	if result.Error != nil {
+		return 0, repo.translate(result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// RestoreByIDs implements AbstractRepository.
+func (repo *abstractRepositoryImpl[T, K]) RestoreByIDs(tx *gorm.DB, ids []K) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	deletedAt, err := repo.deletedAtColumn()
+	if err != nil {
+		return 0, err
+	}
+
+	db, err := repo.byIDs(repo.transCheck(tx).Unscoped().Model(new(T)), ids)
+	if err != nil {
+		return 0, err
+	}
+
+	result := db.Updates(repo.restoreFields(db, deletedAt))
+	if result.Error != nil {
+		return 0, repo.translate(result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,45 @@
+package stdlib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestCreateManyInBatches(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := newTestAccountRepository(db)
+
+	created, err := repo.CreateMany(nil, []*testAccount{{Username: "a"}, {Username: "b"}, {Username: "c"}}, 0)
+	assert.NoError(t, err)
+	assert.Len(t, created, 3)
+	assert.Len(t, captured.sql, 1, "All the entities should fit in a single default batch")
+	assert.Contains(t, captured.last(), `INSERT INTO "test_accounts"`)
+
+	created, err = repo.CreateMany(nil, nil, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, created)
+	assert.Len(t, captured.sql, 1, "No statement should be executed without entities")
+}
+
+func TestBulkWritesByFilterAndIDs(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := newTestAccountRepository(db)
+
+	_, err := repo.UpdateMany(nil, Like("email", "%@old.gg"), map[string]interface{}{"email": nil})
+	assert.NoError(t, err)
+	assert.Contains(t, captured.last(), `UPDATE "test_accounts" SET "email"=NULL WHERE "test_accounts"."email" LIKE '%@old.gg'`)
+
+	_, err = repo.DeleteByIDs(nil, []uint{1, 2})
+	assert.NoError(t, err)
+	assert.Contains(t, captured.last(), `UPDATE "test_accounts" SET "deleted_at"=`)
+	assert.Contains(t, captured.last(), `WHERE "test_accounts"."id" IN (1,2)`)
+
+	_, err = repo.RestoreByIDs(nil, []uint{1, 2})
+	assert.NoError(t, err)
+	assert.Contains(t, captured.last(), `UPDATE "test_accounts" SET "deleted_at"=NULL WHERE "test_accounts"."id" IN (1,2)`)
+
+	_, err = repo.DeleteMany(nil, nil)
+	assert.ErrorIs(t, err, gorm.ErrMissingWhereClause)
+}
@@ -1,11 +1,66 @@
 package stdlib
 
 import (
+	"errors"
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
-	"gorm.io/driver/mysql"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	gormmysql "gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Names of the gorm dialectors, used to generate driver specific SQL.
+const (
+	mysqlDialect    string = "mysql"
+	postgresDialect string = "postgres"
+)
+
+// ErrorTranslator is implemented by the connections able to convert the errors of their
+// driver into the errors of the library (see ConstraintError).
+type ErrorTranslator interface {
+	TranslateError(err error) error
+}
+
+// PostgreSQL error codes (SQLSTATE) translated by the library.
+const (
+	pgUniqueViolation     string = "23505"
+	pgForeignKeyViolation string = "23503"
+)
+
+// MySQL/MariaDB error numbers translated by the library.
+const (
+	mysqlDuplicateEntry   uint16 = 1062
+	mysqlNoReferencedRow  uint16 = 1216
+	mysqlRowIsReferenced  uint16 = 1217
+	mysqlRowIsReferenced2 uint16 = 1451
+	mysqlNoReferencedRow2 uint16 = 1452
+)
+
+// PostgreSQL error codes (SQLSTATE) after which the transaction can be retried.
+const (
+	pgSerializationFailure string = "40001"
+	pgDeadlockDetected     string = "40P01"
+)
+
+// MySQL/MariaDB error number of a deadlock, the transaction is rolled back and can be retried.
+const mysqlLockDeadlock uint16 = 1213
+
+var (
+	// Key (email)=(john@newcore.gg) already exists.
+	pgKeyDetail = regexp.MustCompile(`^Key \((.+?)\)=`)
+
+	// Duplicate entry 'john@newcore.gg' for key 'accounts.idx_accounts_email'
+	mysqlDuplicateKey = regexp.MustCompile(`for key '([^']+)'`)
+
+	// ... a foreign key constraint fails (`db`.`orders`, CONSTRAINT `fk_orders_account` FOREIGN KEY (`account_id`) REFERENCES ...
+	mysqlForeignKey = regexp.MustCompile("`([^`]+)`, CONSTRAINT `([^`]+)` FOREIGN KEY \\(([^)]+)\\)")
 )
 
 // MariaDBConnection is a struct that implements the Connection interface for MariaDB.
@@ -19,7 +74,7 @@ func (m *MariaDBConnection) Connect(cfg StdLibConfiguration) (Conn, error) {
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
 		cfg.DBUser, cfg.DBPassword, cfg.DBHost, cfg.DBPort, cfg.DBDatabase)
 
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(gormmysql.Open(dsn), &gorm.Config{})
 	if err != nil {
 		return Conn{}, err
 	}
@@ -39,3 +94,187 @@ func (p *PostgresConnection) Connect(cfg StdLibConfiguration) (Conn, error) {
 
 	return Conn{Gorm: db}, nil
 }
+
+// Helper function onConflictClause builds the upsert clause for the dialect.
+// PostgreSQL generates ON CONFLICT (conflictColumns) DO UPDATE, while MySQL/MariaDB generates
+// ON DUPLICATE KEY UPDATE, which resolves the conflict with any unique index so the columns are not used.
+// When updateColumns is empty every column is updated.
+func onConflictClause(dialect string, conflictColumns, updateColumns []string) clause.OnConflict {
+	onConflict := clause.OnConflict{}
+
+	if dialect != mysqlDialect {
+		for _, column := range conflictColumns {
+			onConflict.Columns = append(onConflict.Columns, clause.Column{Name: column})
+		}
+	}
+
+	if len(updateColumns) == 0 {
+		onConflict.UpdateAll = true
+	} else {
+		onConflict.DoUpdates = clause.AssignmentColumns(updateColumns)
+	}
+
+	return onConflict
+}
+
+// TranslateError converts a PostgreSQL unique or foreign key violation (SQLSTATE 23505 / 23503)
+// into a *ConstraintError carrying the constraint, table and columns reported by the server.
+// Any other error is returned as it is.
+func (p *PostgresConnection) TranslateError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	var kind error
+	switch pgErr.Code {
+	case pgUniqueViolation:
+		kind = ErrDuplicateKey
+	case pgForeignKeyViolation:
+		kind = ErrForeignKey
+	default:
+		return err
+	}
+
+	columns := []string{}
+	if pgErr.ColumnName != "" {
+		columns = append(columns, pgErr.ColumnName)
+	} else if match := pgKeyDetail.FindStringSubmatch(pgErr.Detail); match != nil {
+		columns = splitColumns(match[1], `"`)
+	}
+
+	return &ConstraintError{
+		Kind:       kind,
+		Constraint: pgErr.ConstraintName,
+		Table:      pgErr.TableName,
+		Columns:    columns,
+		Err:        err,
+	}
+}
+
+// TranslateError converts a MySQL/MariaDB duplicate entry (1062) or foreign key violation (1216, 1217, 1451, 1452)
+// into a *ConstraintError carrying the constraint, table and columns found in the error message.
+// MySQL does not report the columns of a duplicate entry, the repositories resolve them from the entity schema.
+// Any other error is returned as it is.
+func (m *MariaDBConnection) TranslateError(err error) error {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return err
+	}
+
+	switch mysqlErr.Number {
+	case mysqlDuplicateEntry:
+		constraintErr := &ConstraintError{Kind: ErrDuplicateKey, Columns: []string{}, Err: err}
+		if match := mysqlDuplicateKey.FindStringSubmatch(mysqlErr.Message); match != nil {
+			// MySQL 8 reports the key as table.key
+			constraintErr.Constraint = match[1]
+			if table, key, ok := strings.Cut(match[1], "."); ok {
+				constraintErr.Table, constraintErr.Constraint = table, key
+			}
+		}
+		return constraintErr
+
+	case mysqlNoReferencedRow, mysqlRowIsReferenced, mysqlRowIsReferenced2, mysqlNoReferencedRow2:
+		constraintErr := &ConstraintError{Kind: ErrForeignKey, Columns: []string{}, Err: err}
+		if match := mysqlForeignKey.FindStringSubmatch(mysqlErr.Message); match != nil {
+			constraintErr.Table = match[1]
+			constraintErr.Constraint = match[2]
+			constraintErr.Columns = splitColumns(match[3], "`")
+		}
+		return constraintErr
+	}
+
+	return err
+}
+
+// Helper function translateError converts a driver error of the dialect into the errors
+// of the library using the ErrorTranslator of its connection.
+// The errors already translated by gorm (when gorm.Config.TranslateError is enabled) are converted too.
+func translateError(dialect string, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return &ConstraintError{Kind: ErrDuplicateKey, Columns: []string{}, Err: err}
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return &ConstraintError{Kind: ErrForeignKey, Columns: []string{}, Err: err}
+	}
+
+	var translator ErrorTranslator
+	switch dialect {
+	case postgresDialect:
+		translator = &PostgresConnection{}
+	case mysqlDialect:
+		translator = &MariaDBConnection{}
+	default:
+		return err
+	}
+	return translator.TranslateError(err)
+}
+
+// Helper function splitColumns splits a list of columns like `"a", "b"` removing the quotes.
+func splitColumns(list, quote string) []string {
+	columns := strings.Split(list, ",")
+	for i, column := range columns {
+		columns[i] = strings.Trim(strings.TrimSpace(column), quote)
+	}
+	return columns
+}
+
+// IsRetryable reports whether err is a serialization failure or a deadlock (PostgreSQL 40001 / 40P01,
+// MySQL/MariaDB 1213), after which the whole transaction can be run again. It is the default
+// classifier of RetryPolicy.
+func IsRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == pgSerializationFailure || pgErr.Code == pgDeadlockDetected
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == mysqlLockDeadlock
+	}
+	return false
+}
+
+// Helper function timeoutStatements returns the statements applying the timeouts to a transaction, and the ones
+// restoring the connection before it is released. PostgreSQL scopes them to the transaction with SET LOCAL,
+// while MariaDB sets session variables (max_statement_time and innodb_lock_wait_timeout, in seconds)
+// that must be restored. MySQL has no statement timeout, ErrStatementTimeoutUnsupported is returned.
+// A zero timeout is not applied.
+func timeoutStatements(dialector gorm.Dialector, statementTimeout, lockTimeout time.Duration) (apply, restore []string, err error) {
+	if dialector.Name() == mysqlDialect {
+		if statementTimeout > 0 {
+			if isMySQLServer(dialector) {
+				return nil, nil, ErrStatementTimeoutUnsupported
+			}
+			seconds := strconv.FormatFloat(statementTimeout.Seconds(), 'f', -1, 64)
+			apply = append(apply, "SET SESSION max_statement_time = "+seconds)
+			restore = append(restore, "SET SESSION max_statement_time = DEFAULT")
+		}
+		if lockTimeout > 0 {
+			seconds := (lockTimeout + time.Second - 1) / time.Second
+			apply = append(apply, fmt.Sprintf("SET SESSION innodb_lock_wait_timeout = %d", seconds))
+			restore = append(restore, "SET SESSION innodb_lock_wait_timeout = DEFAULT")
+		}
+		return apply, restore, nil
+	}
+
+	if statementTimeout > 0 {
+		apply = append(apply, fmt.Sprintf("SET LOCAL statement_timeout = %d", max(statementTimeout.Milliseconds(), 1)))
+	}
+	if lockTimeout > 0 {
+		apply = append(apply, fmt.Sprintf("SET LOCAL lock_timeout = %d", max(lockTimeout.Milliseconds(), 1)))
+	}
+	return apply, nil, nil
+}
+
+// Helper function isMySQLServer reports whether the mysql dialector is connected to MySQL rather than MariaDB,
+// an unknown server version (e.g. SkipInitializeWithVersion) is assumed to be MariaDB.
+func isMySQLServer(dialector gorm.Dialector) bool {
+	mysqlDialector, ok := dialector.(*gormmysql.Dialector)
+	if !ok || mysqlDialector.Config == nil || mysqlDialector.ServerVersion == "" {
+		return false
+	}
+	return !strings.Contains(mysqlDialector.ServerVersion, "MariaDB")
+}
@@ -0,0 +1,112 @@
+package stdlib
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestTranslatePostgresErrors(t *testing.T) {
+	duplicate := &pgconn.PgError{Code: "23505", Message: "duplicate key value violates unique constraint"}
+	err := translateError(postgresDialect, duplicate)
+	assert.ErrorIs(t, err, ErrDuplicateKey)
+	assert.ErrorIs(t, err, ErrRecordConflict)
+
+	var pgErr *pgconn.PgError
+	assert.True(t, errors.As(err, &pgErr), "The driver error should still be wrapped")
+
+	err = translateError(postgresDialect, &pgconn.PgError{Code: "23503"})
+	assert.ErrorIs(t, err, ErrForeignKey)
+	assert.NotErrorIs(t, err, ErrRecordConflict)
+
+	other := &pgconn.PgError{Code: "42P01"}
+	assert.Equal(t, other, translateError(postgresDialect, other))
+}
+
+func TestTranslateMySQLErrors(t *testing.T) {
+	assert.ErrorIs(t, translateError(mysqlDialect, &mysql.MySQLError{Number: 1062}), ErrDuplicateKey)
+	assert.ErrorIs(t, translateError(mysqlDialect, &mysql.MySQLError{Number: 1451}), ErrForeignKey)
+	assert.ErrorIs(t, translateError(mysqlDialect, &mysql.MySQLError{Number: 1452}), ErrForeignKey)
+}
+
+func TestTranslateErrorKeepsLibraryAndGormErrors(t *testing.T) {
+	assert.Nil(t, translateError(postgresDialect, nil))
+	assert.Equal(t, ErrRecordNotFound, translateError(postgresDialect, gorm.ErrRecordNotFound))
+	assert.ErrorIs(t, translateError(mysqlDialect, gorm.ErrDuplicatedKey), ErrDuplicateKey)
+	assert.ErrorIs(t, ErrStaleEntity, ErrRecordConflict)
+}
+
+func TestTranslatePostgresConstraintDetails(t *testing.T) {
+	err := (&PostgresConnection{}).TranslateError(&pgconn.PgError{
+		Code:           "23505",
+		ConstraintName: "idx_accounts_tenant_email",
+		TableName:      "accounts",
+		Detail:         `Key (tenant_id, "email")=(1, john@newcore.gg) already exists.`,
+	})
+
+	var constraintErr *ConstraintError
+	assert.True(t, errors.As(err, &constraintErr))
+	assert.Equal(t, "idx_accounts_tenant_email", constraintErr.Constraint)
+	assert.Equal(t, "accounts", constraintErr.Table)
+	assert.Equal(t, []string{"tenant_id", "email"}, constraintErr.Columns)
+}
+
+func TestTranslateMySQLConstraintDetails(t *testing.T) {
+	err := (&MariaDBConnection{}).TranslateError(&mysql.MySQLError{
+		Number:  1062,
+		Message: "Duplicate entry 'john@newcore.gg' for key 'accounts.idx_accounts_email'",
+	})
+
+	var constraintErr *ConstraintError
+	assert.True(t, errors.As(err, &constraintErr))
+	assert.Equal(t, "idx_accounts_email", constraintErr.Constraint)
+	assert.Equal(t, "accounts", constraintErr.Table)
+
+	err = (&MariaDBConnection{}).TranslateError(&mysql.MySQLError{
+		Number: 1452,
+		Message: "Cannot add or update a child row: a foreign key constraint fails " +
+			"(`shop`.`orders`, CONSTRAINT `fk_orders_account` FOREIGN KEY (`account_id`) REFERENCES `accounts` (`id`))",
+	})
+	assert.True(t, errors.As(err, &constraintErr))
+	assert.ErrorIs(t, err, ErrForeignKey)
+	assert.Equal(t, "fk_orders_account", constraintErr.Constraint)
+	assert.Equal(t, "orders", constraintErr.Table)
+	assert.Equal(t, []string{"account_id"}, constraintErr.Columns)
+}
+
+type testUniqueAccount struct {
+	ID    uint   `gorm:"primaryKey"`
+	Email string `gorm:"uniqueIndex:idx_accounts_email"`
+}
+
+func (a *testUniqueAccount) GetID() uint {
+	return a.ID
+}
+
+func TestRepositoryResolvesConstraintColumns(t *testing.T) {
+	db, _ := newDryRunMySQLDB(t)
+	repo := &abstractRepositoryImpl[*testUniqueAccount, uint]{gorm: db}
+
+	err := repo.translate(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'x' for key 'idx_accounts_email'"})
+
+	var constraintErr *ConstraintError
+	assert.True(t, errors.As(err, &constraintErr))
+	assert.Equal(t, []string{"email"}, constraintErr.Columns)
+	assert.Contains(t, err.Error(), "duplicate key on idx_accounts_email (email)")
+}
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, IsRetryable(&pgconn.PgError{Code: "40001"}))
+	assert.True(t, IsRetryable(fmt.Errorf("transfer: %w", &pgconn.PgError{Code: "40P01"})))
+	assert.True(t, IsRetryable(&mysql.MySQLError{Number: 1213}))
+
+	assert.False(t, IsRetryable(&pgconn.PgError{Code: "23505"}))
+	assert.False(t, IsRetryable(&mysql.MySQLError{Number: 1062}))
+	assert.False(t, IsRetryable(errors.New("connection refused")))
+	assert.False(t, IsRetryable(nil))
+}
@@ -0,0 +1,233 @@
+package stdlib
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ErrUnknownColumn is returned when a filter references a column that is not part of the entity schema.
+var ErrUnknownColumn = errors.New("unknown column")
+
+// Filter is a composable condition that can be executed by the repository
+// through FindWhere, FirstWhere, CountWhere and ExistsWhere.
+//
+// Filters are built with Eq, Neq, Gt, Gte, Lt, Lte, In, Like, Between, IsNull, IsNotNull
+// and combined with And / Or. Column names are validated against the GORM schema of the
+// entity (both the column name and the struct field name are accepted), and values are
+// always sent as bound parameters.
+//
+//	filter := stdlib.And(
+//		stdlib.Eq("status", "active"),
+//		stdlib.Or(stdlib.Like("email", "%@newcore.gg"), stdlib.IsNull("deleted_by")),
+//	)
+//	accounts, err := repo.FindWhere(filter)
+type Filter interface {
+	// build resolves the filter against the schema of the entity.
+	// A nil expression means the filter has no condition.
+	build(sch *schema.Schema) (clause.Expression, error)
+}
+
+type operator int
+
+const (
+	opEq operator = iota
+	opNeq
+	opGt
+	opGte
+	opLt
+	opLte
+	opIn
+	opLike
+	opBetween
+	opIsNull
+	opIsNotNull
+)
+
+type conditionFilter struct {
+	column string
+	op     operator
+	values []any
+}
+
+type groupFilter struct {
+	or      bool
+	filters []Filter
+}
+
+// Eq matches the entities where column is equal to value.
+func Eq(column string, value any) Filter {
+	return conditionFilter{column: column, op: opEq, values: []any{value}}
+}
+
+// Neq matches the entities where column is not equal to value.
+func Neq(column string, value any) Filter {
+	return conditionFilter{column: column, op: opNeq, values: []any{value}}
+}
+
+// Gt matches the entities where column is greater than value.
+func Gt(column string, value any) Filter {
+	return conditionFilter{column: column, op: opGt, values: []any{value}}
+}
+
+// Gte matches the entities where column is greater than or equal to value.
+func Gte(column string, value any) Filter {
+	return conditionFilter{column: column, op: opGte, values: []any{value}}
+}
+
+// Lt matches the entities where column is less than value.
+func Lt(column string, value any) Filter {
+	return conditionFilter{column: column, op: opLt, values: []any{value}}
+}
+
+// Lte matches the entities where column is less than or equal to value.
+func Lte(column string, value any) Filter {
+	return conditionFilter{column: column, op: opLte, values: []any{value}}
+}
+
+// In matches the entities where column is one of values.
+func In(column string, values ...any) Filter {
+	return conditionFilter{column: column, op: opIn, values: values}
+}
+
+// Like matches the entities where column matches the LIKE pattern.
+func Like(column string, pattern string) Filter {
+	return conditionFilter{column: column, op: opLike, values: []any{pattern}}
+}
+
+// Between matches the entities where column is between from and to (both inclusive).
+func Between(column string, from, to any) Filter {
+	return conditionFilter{column: column, op: opBetween, values: []any{from, to}}
+}
+
+// IsNull matches the entities where column is NULL.
+func IsNull(column string) Filter {
+	return conditionFilter{column: column, op: opIsNull}
+}
+
+// IsNotNull matches the entities where column is not NULL.
+func IsNotNull(column string) Filter {
+	return conditionFilter{column: column, op: opIsNotNull}
+}
+
+// And matches the entities that satisfy all the filters, every entity when there is none.
+func And(filters ...Filter) Filter {
+	return groupFilter{filters: filters}
+}
+
+// Or matches the entities that satisfy at least one of the filters, no entity when there is none
+// (nil filters are ignored), so an empty list of allowed filters never exposes the whole table.
+func Or(filters ...Filter) Filter {
+	return groupFilter{or: true, filters: filters}
+}
+
+func (f conditionFilter) build(sch *schema.Schema) (clause.Expression, error) {
+	name, err := resolveColumn(sch, f.column)
+	if err != nil {
+		return nil, err
+	}
+	column := clause.Column{Table: clause.CurrentTable, Name: name}
+
+	switch f.op {
+	case opEq:
+		return clause.Eq{Column: column, Value: f.values[0]}, nil
+	case opNeq:
+		return clause.Neq{Column: column, Value: f.values[0]}, nil
+	case opGt:
+		return clause.Gt{Column: column, Value: f.values[0]}, nil
+	case opGte:
+		return clause.Gte{Column: column, Value: f.values[0]}, nil
+	case opLt:
+		return clause.Lt{Column: column, Value: f.values[0]}, nil
+	case opLte:
+		return clause.Lte{Column: column, Value: f.values[0]}, nil
+	case opIn:
+		return clause.IN{Column: column, Values: f.values}, nil
+	case opLike:
+		return clause.Like{Column: column, Value: f.values[0]}, nil
+	case opBetween:
+		return clause.Expr{SQL: "? BETWEEN ? AND ?", Vars: []any{column, f.values[0], f.values[1]}}, nil
+	case opIsNull:
+		return clause.Eq{Column: column, Value: nil}, nil
+	case opIsNotNull:
+		return clause.Neq{Column: column, Value: nil}, nil
+	}
+	return nil, fmt.Errorf("unsupported filter operator %d", f.op)
+}
+
+func (f groupFilter) build(sch *schema.Schema) (clause.Expression, error) {
+	exprs := make([]clause.Expression, 0, len(f.filters))
+	matchesAll := false
+	for _, filter := range f.filters {
+		if filter == nil {
+			continue
+		}
+		expr, err := filter.build(sch)
+		if err != nil {
+			return nil, err
+		}
+		if expr != nil {
+			exprs = append(exprs, expr)
+		} else {
+			matchesAll = true
+		}
+	}
+
+	switch {
+	case f.or && matchesAll:
+		return nil, nil
+	case f.or && len(exprs) == 0:
+		// an empty disjunction is false, it must not fall back to no condition
+		return clause.Expr{SQL: "1 = 0"}, nil
+	case len(exprs) == 0:
+		return nil, nil
+	case len(exprs) == 1:
+		return exprs[0], nil
+	case f.or:
+		return clause.Or(exprs...), nil
+	default:
+		return clause.And(exprs...), nil
+	}
+}
+
+// Helper function applyFilter resolves the filter against the schema and adds it to the query.
+func applyFilter(db *gorm.DB, sch *schema.Schema, filter Filter) (*gorm.DB, error) {
+	if filter == nil {
+		return db, nil
+	}
+
+	expr, err := filter.build(sch)
+	if err != nil {
+		return nil, err
+	}
+	if expr == nil {
+		return db, nil
+	}
+	return db.Clauses(clause.Where{Exprs: []clause.Expression{expr}}), nil
+}
+
+// Helper function resolveColumn returns the database column of a field, accepting either
+// the column name or the struct field name.
+func resolveColumn(sch *schema.Schema, name string) (string, error) {
+	field := sch.LookUpField(name)
+	if field == nil || field.DBName == "" {
+		return "", fmt.Errorf("%w: %s", ErrUnknownColumn, name)
+	}
+	return field.DBName, nil
+}
+
+// Helper function resolveColumns resolves every name with resolveColumn.
+func resolveColumns(sch *schema.Schema, names []string) ([]string, error) {
+	columns := make([]string, 0, len(names))
+	for _, name := range names {
+		column, err := resolveColumn(sch, name)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
@@ -0,0 +1,75 @@
+package stdlib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindWhereBuildsFilters(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := newTestAccountRepository(db)
+
+	_, err := repo.FindWhere(And(
+		Eq("username", "john"),
+		Or(Like("Email", "%@newcore.gg"), IsNull("email")),
+		In("id", 1, 2, 3),
+		Between("created_at", "2024-01-01", "2024-12-31"),
+	))
+	assert.NoError(t, err)
+
+	sql := captured.last()
+	assert.Contains(t, sql, `"test_accounts"."username" = 'john'`)
+	assert.Contains(t, sql, `("test_accounts"."email" LIKE '%@newcore.gg' OR "test_accounts"."email" IS NULL)`)
+	assert.Contains(t, sql, `"test_accounts"."id" IN (1,2,3)`)
+	assert.Contains(t, sql, `"test_accounts"."created_at" BETWEEN '2024-01-01' AND '2024-12-31'`)
+	assert.Contains(t, sql, `"test_accounts"."deleted_at" IS NULL`)
+}
+
+func TestEmptyGroupFilters(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := newTestAccountRepository(db)
+
+	var allowed []Filter
+	_, err := repo.FindWhere(Or(allowed...))
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM "test_accounts" WHERE 1 = 0 AND "test_accounts"."deleted_at" IS NULL`, captured.last(), "An empty Or should match nothing")
+
+	_, err = repo.FindWhere(Or(nil, nil))
+	assert.NoError(t, err)
+	assert.Contains(t, captured.last(), "WHERE 1 = 0")
+
+	_, err = repo.FindWhere(And())
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM "test_accounts" WHERE "test_accounts"."deleted_at" IS NULL`, captured.last(), "An empty And should match everything")
+
+	_, err = repo.FindWhere(Or(And(), Eq("username", "john")))
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM "test_accounts" WHERE "test_accounts"."deleted_at" IS NULL`, captured.last())
+}
+
+func TestFindWhereRejectsUnknownColumns(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := newTestAccountRepository(db)
+
+	_, err := repo.FirstByKey("1=1; DROP TABLE test_accounts; --", "x")
+	assert.ErrorIs(t, err, ErrUnknownColumn)
+
+	_, err = repo.FindWhere(Or(Eq("username", "john"), Neq("password", "x")))
+	assert.ErrorIs(t, err, ErrUnknownColumn)
+	assert.Empty(t, captured.sql, "No statement should be executed for invalid filters")
+}
+
+func TestCountAndExistsWhere(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := newTestAccountRepository(db)
+
+	_, err := repo.CountWhere(Gte("id", 10))
+	assert.NoError(t, err)
+	assert.Contains(t, captured.last(), `SELECT count(*) FROM "test_accounts" WHERE "test_accounts"."id" >= 10`)
+
+	_, err = repo.ExistsWhere(Eq("username", "john"))
+	assert.NoError(t, err)
+	assert.Contains(t, captured.last(), `SELECT 1 FROM "test_accounts" WHERE "test_accounts"."username" = 'john'`)
+	assert.Contains(t, captured.last(), "LIMIT 1")
+}
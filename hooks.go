@@ -0,0 +1,135 @@
+package stdlib
+
+import "gorm.io/gorm"
+
+// Operation is the kind of write described by a Change.
+type Operation string
+
+const (
+	OperationCreate  Operation = "create"
+	OperationUpdate  Operation = "update"
+	OperationDelete  Operation = "delete"
+	OperationRestore Operation = "restore"
+
+	// OperationHardDelete is a permanent delete (HardDelete), unlike OperationDelete it cannot be restored.
+	OperationHardDelete Operation = "hard_delete"
+)
+
+// Change describes a write of the repository to its hooks.
+type Change[T Identifiable[K], K ID] struct {
+	Operation Operation
+
+	// ID of the written entity, it is only known after the insert on Create.
+	ID K
+
+	// Entity is the new entity on Create and Update, the zero value otherwise.
+	// Before hooks can modify it (or replace it) before it is written.
+	Entity T
+
+	// Fields are the fields written by UpdateSpecific, nil otherwise.
+	// Before hooks can modify them, the map of the caller is not changed.
+	Fields map[string]interface{}
+
+	auditBefore map[string]any
+}
+
+// Hook is called around a write of the repository with the transaction of the write.
+// A before hook returning an error vetoes the write, and an after hook returning an error fails it
+// (rolling back the transaction started by the repository); the error is returned to the caller as is.
+type Hook[T Identifiable[K], K ID] func(tx *gorm.DB, change *Change[T, K]) error
+
+// BeforeWriteHook can be implemented by a concrete repository (the self reference of CreateRepository)
+// to run a before hook on every write, before the hooks registered with BeforeWrite.
+type BeforeWriteHook[T Identifiable[K], K ID] interface {
+	BeforeWrite(tx *gorm.DB, change *Change[T, K]) error
+}
+
+// AfterWriteHook can be implemented by a concrete repository (the self reference of CreateRepository)
+// to run an after hook on every write, before the hooks registered with AfterWrite.
+type AfterWriteHook[T Identifiable[K], K ID] interface {
+	AfterWrite(tx *gorm.DB, change *Change[T, K]) error
+}
+
+// RepositoryOption customizes a repository created with CreateRepository.
+type RepositoryOption[T Identifiable[K], K ID] func(*abstractRepositoryImpl[T, K])
+
+type writeHooks[T Identifiable[K], K ID] struct {
+	before []Hook[T, K]
+	after  []Hook[T, K]
+}
+
+// BeforeWrite registers a hook that runs before Create, Update, UpdateSpecific, Delete, HardDelete and Restore,
+// and can veto them by returning an error. Hooks run in the order they are registered.
+//
+//	repo.AbstractRepository = stdlib.CreateRepository(gormDB, repo,
+//		stdlib.BeforeWrite(func(tx *gorm.DB, change *stdlib.Change[*models.Account, uint]) error {
+//			deleted := change.Operation == stdlib.OperationDelete || change.Operation == stdlib.OperationHardDelete
+//			if deleted && change.ID == rootAccountID {
+//				return ErrRootAccount
+//			}
+//			return nil
+//		}),
+//	)
+//
+// When a repository has hooks, the writes called without a transaction run within one,
+// so the hooks and the write are committed (or rolled back) together.
+// The bulk writes (CreateMany, UpdateMany, DeleteMany, ...), PurgeDeletedBefore and the upserts do not run the hooks.
+func BeforeWrite[T Identifiable[K], K ID](hook Hook[T, K]) RepositoryOption[T, K] {
+	return func(repo *abstractRepositoryImpl[T, K]) {
+		repo.hooks.before = append(repo.hooks.before, hook)
+	}
+}
+
+// AfterWrite registers a hook that runs after a successful Create, Update, UpdateSpecific, Delete, HardDelete and Restore,
+// within the same transaction, see BeforeWrite. Hooks run in the order they are registered.
+func AfterWrite[T Identifiable[K], K ID](hook Hook[T, K]) RepositoryOption[T, K] {
+	return func(repo *abstractRepositoryImpl[T, K]) {
+		repo.hooks.after = append(repo.hooks.after, hook)
+	}
+}
+
+// Helper function write runs the write between the before and after hooks, within a new transaction
+// if there are hooks and none is given. The tenant (see WithTenantScope) and the actor (see Auditable)
+// are stamped after the before hooks.
+func (repo *abstractRepositoryImpl[T, K]) write(tx *gorm.DB, change *Change[T, K], write func(tx *gorm.DB) error) error {
+	if tx == nil {
+		tx = repo.contextTransaction()
+	}
+
+	hooked := len(repo.hooks.before) > 0 || len(repo.hooks.after) > 0
+	if hooked && tx == nil {
+		return repo.transCheck(nil).Transaction(func(tx *gorm.DB) error {
+			return repo.write(tx, change, write)
+		})
+	}
+
+	tx = repo.transCheck(tx)
+	for _, hook := range repo.hooks.before {
+		if err := hook(tx, change); err != nil {
+			return err
+		}
+	}
+
+	if change.Fields != nil {
+		if err := repo.withoutTenantField(tx, change.Fields); err != nil {
+			return err
+		}
+		repo.stampActorField(tx, change.Fields)
+	} else if change.Operation == OperationCreate || change.Operation == OperationUpdate {
+		if err := repo.stampTenant(tx, &change.Entity); err != nil {
+			return err
+		}
+		repo.stampActor(tx, change.Operation == OperationCreate, &change.Entity)
+	}
+
+	if err := write(tx); err != nil {
+		return err
+	}
+
+	for _, hook := range repo.hooks.after {
+		if err := hook(tx, change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package stdlib
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testHookedAccountRepository struct {
+	AbstractRepository[*testAccount, uint]
+	calls []string
+}
+
+func (r *testHookedAccountRepository) BeforeWrite(tx *gorm.DB, change *Change[*testAccount, uint]) error {
+	r.calls = append(r.calls, "self:before:"+string(change.Operation))
+	return nil
+}
+
+func newTestHookedAccountRepository(gormDB *gorm.DB, opts ...RepositoryOption[*testAccount, uint]) *testHookedAccountRepository {
+	repo := &testHookedAccountRepository{}
+	repo.AbstractRepository = CreateRepository(gormDB, repo, opts...)
+	return repo
+}
+
+func TestWriteHooksRunAroundWrites(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	var repo *testHookedAccountRepository
+	repo = newTestHookedAccountRepository(db,
+		BeforeWrite(func(tx *gorm.DB, change *Change[*testAccount, uint]) error {
+			repo.calls = append(repo.calls, "before:"+string(change.Operation))
+			if change.Entity != nil {
+				change.Entity.Email = "stamped@newcore.gg"
+			}
+			if change.Fields != nil {
+				change.Fields["email"] = "stamped@newcore.gg"
+			}
+			return nil
+		}),
+		AfterWrite(func(tx *gorm.DB, change *Change[*testAccount, uint]) error {
+			repo.calls = append(repo.calls, "after:"+string(change.Operation))
+			return nil
+		}),
+	)
+	tx := db.Session(&gorm.Session{})
+
+	created, err := repo.Create(tx, &testAccount{Username: "john"})
+	assert.NoError(t, err)
+	assert.Equal(t, "stamped@newcore.gg", created.Email, "Before hooks should be able to modify the entity")
+	assert.Equal(t, []string{"self:before:create", "before:create", "after:create"}, repo.calls)
+
+	repo.calls = nil
+	fields := map[string]interface{}{"username": "john"}
+	assert.ErrorIs(t, repo.UpdateSpecific(tx, 1, fields), ErrRecordNotFound)
+	assert.Contains(t, captured.sql[len(captured.sql)-2], `"email"='stamped@newcore.gg'`)
+	assert.NotContains(t, fields, "email", "The map of the caller should not be changed")
+	assert.Equal(t, []string{"self:before:update", "before:update"}, repo.calls, "After hooks should not run when the write fails")
+}
+
+func TestBeforeWriteHookVetoes(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	errProtected := errors.New("protected account")
+	repo := newTestHookedAccountRepository(db,
+		BeforeWrite(func(tx *gorm.DB, change *Change[*testAccount, uint]) error {
+			if change.Operation == OperationDelete && change.ID == 1 {
+				return errProtected
+			}
+			return nil
+		}),
+	)
+
+	assert.ErrorIs(t, repo.Delete(db.Session(&gorm.Session{}), 1), errProtected)
+	assert.Empty(t, captured.sql, "A vetoed write should not reach the database")
+}
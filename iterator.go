@@ -0,0 +1,75 @@
+package stdlib
+
+import (
+	"errors"
+	"iter"
+)
+
+// errStopIteration stops FindInBatches when the consumer of Iterate breaks the loop.
+var errStopIteration = errors.New("stop iteration")
+
+// FindInBatches implements AbstractRepository.
+func (repo *abstractRepositoryImpl[T, K]) FindInBatches(filter Filter, batchSize int, fn func(batch []T) error) error {
+	if batchSize < 1 {
+		batchSize = DefaultBatchSize
+	}
+
+	sch, err := repo.schema()
+	if err != nil {
+		return err
+	}
+
+	var after Filter
+	for {
+		if repo.ctx != nil {
+			if err := repo.ctx.Err(); err != nil {
+				return err
+			}
+		}
+
+		db, err := repo.query(And(filter, after), &queryOptions{sorts: repo.keySorts()})
+		if err != nil {
+			return err
+		}
+
+		var batch []T
+		if err := db.Limit(batchSize).Find(&batch).Error; err != nil {
+			return repo.translate(err)
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < batchSize {
+			return nil
+		}
+
+		values, err := repo.keyValues(sch, batch[len(batch)-1].GetID())
+		if err != nil {
+			return err
+		}
+		after = keysetFilter{columns: repo.primaryKeys(), values: values}
+	}
+}
+
+// Iterate implements AbstractRepository.
+func (repo *abstractRepositoryImpl[T, K]) Iterate(filter Filter, batchSize int) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		err := repo.FindInBatches(filter, batchSize, func(batch []T) error {
+			for _, entity := range batch {
+				if !yield(entity, nil) {
+					return errStopIteration
+				}
+			}
+			return nil
+		})
+
+		if err != nil && !errors.Is(err, errStopIteration) {
+			var zeroValue T
+			yield(zeroValue, err)
+		}
+	}
+}
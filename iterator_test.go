@@ -0,0 +1,81 @@
+package stdlib
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// Helper: serve the given batches, in order, as the results of the dry run queries
+func serveBatches(db *gorm.DB, batches ...[]*testAccount) {
+	_ = db.Callback().Query().After("gorm:query").Register("test:batches", func(db *gorm.DB) {
+		if dest, ok := db.Statement.Dest.(*[]*testAccount); ok && len(batches) > 0 {
+			*dest = batches[0]
+			batches = batches[1:]
+		}
+	})
+}
+
+func TestFindInBatchesUsesKeyset(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	serveBatches(db, []*testAccount{{ID: 1}, {ID: 2}}, []*testAccount{{ID: 5}})
+	repo := newTestAccountRepository(db)
+
+	var seen []uint
+	err := repo.FindInBatches(Eq("username", "john"), 2, func(batch []*testAccount) error {
+		for _, account := range batch {
+			seen = append(seen, account.ID)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{1, 2, 5}, seen)
+	assert.Len(t, captured.sql, 2, "The short batch should end the walk")
+	assert.Contains(t, captured.sql[0], `WHERE "test_accounts"."username" = 'john' AND "test_accounts"."deleted_at" IS NULL ORDER BY "test_accounts"."id" LIMIT 2`)
+	assert.Contains(t, captured.sql[1], `WHERE ("test_accounts"."username" = 'john' AND "test_accounts"."id" > 2)`)
+}
+
+func TestFindInBatchesStopsOnError(t *testing.T) {
+	db, _ := newDryRunDB(t)
+	serveBatches(db, []*testAccount{{ID: 1}}, []*testAccount{{ID: 2}})
+	repo := newTestAccountRepository(db)
+
+	failure := errors.New("export failed")
+	err := repo.FindInBatches(nil, 1, func(batch []*testAccount) error {
+		return failure
+	})
+	assert.ErrorIs(t, err, failure)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = repo.WithContext(ctx).FindInBatches(nil, 1, func(batch []*testAccount) error {
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestIterate(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	serveBatches(db, []*testAccount{{ID: 1}, {ID: 2}}, []*testAccount{{ID: 3}, {ID: 4}})
+	repo := newTestAccountRepository(db)
+
+	var seen []uint
+	for account, err := range repo.Iterate(nil, 2) {
+		assert.NoError(t, err)
+		seen = append(seen, account.ID)
+		if account.ID == 3 {
+			break
+		}
+	}
+	assert.Equal(t, []uint{1, 2, 3}, seen)
+	assert.Len(t, captured.sql, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, err := range repo.WithContext(ctx).Iterate(nil, 2) {
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+}
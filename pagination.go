@@ -0,0 +1,152 @@
+package stdlib
+
+import (
+	"encoding/base64"
+	"errors"
+	"math"
+
+	"github.com/bytedance/sonic"
+)
+
+// DefaultPageSize is the page size used when a non positive size is requested.
+const DefaultPageSize int = 20
+
+// MaxPageSize is the largest page size of the paginated finders, a greater size is clamped to it.
+// The sizes usually come from the query parameters of the clients, so it bounds the rows loaded per request.
+// It can be changed when the application starts.
+var MaxPageSize int = 100
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded.
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+// Page is a slice of entities of type T returned by the paginated finders.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int64  `json:"total"`
+	Page       int    `json:"page,omitempty"`
+	Size       int    `json:"size"`
+	NextCursor string `json:"nextCursor,omitempty"`
+	HasMore    bool   `json:"hasMore"`
+}
+
+// FindPage implements AbstractRepository.
+func (repo *abstractRepositoryImpl[T, K]) FindPage(page, size int, opts ...QueryOption) (Page[T], error) {
+	page, size = normalizePage(page, size)
+	result := Page[T]{Page: page, Size: size}
+
+	count, err := repo.model(nil, nil)
+	if err != nil {
+		return Page[T]{}, err
+	}
+	if err := count.Count(&result.Total).Error; err != nil {
+		return Page[T]{}, repo.translate(err)
+	}
+
+	// the primary key is always the last sort, so the pages are stable
+	options := newQueryOptions(opts)
+	options.sorts = append(options.sorts, repo.keySorts()...)
+
+	db, err := repo.query(nil, options)
+	if err != nil {
+		return Page[T]{}, err
+	}
+
+	offset := (page - 1) * size
+	if err := db.Offset(offset).Limit(size).Find(&result.Items).Error; err != nil {
+		return Page[T]{}, repo.translate(err)
+	}
+
+	result.HasMore = int64(offset)+int64(len(result.Items)) < result.Total
+	return result, nil
+}
+
+// FindAfter implements AbstractRepository.
+func (repo *abstractRepositoryImpl[T, K]) FindAfter(cursor string, size int) (Page[T], error) {
+	_, size = normalizePage(1, size)
+	result := Page[T]{Size: size}
+
+	count, err := repo.model(nil, nil)
+	if err != nil {
+		return Page[T]{}, err
+	}
+	if err := count.Count(&result.Total).Error; err != nil {
+		return Page[T]{}, repo.translate(err)
+	}
+
+	var filter Filter
+	if cursor != "" {
+		after, err := decodeCursor[K](cursor)
+		if err != nil {
+			return Page[T]{}, err
+		}
+
+		sch, err := repo.schema()
+		if err != nil {
+			return Page[T]{}, err
+		}
+		values, err := repo.keyValues(sch, after)
+		if err != nil {
+			return Page[T]{}, err
+		}
+		filter = keysetFilter{columns: repo.primaryKeys(), values: values}
+	}
+
+	db, err := repo.query(filter, &queryOptions{sorts: repo.keySorts()})
+	if err != nil {
+		return Page[T]{}, err
+	}
+
+	// one extra entity is requested to know if there is a next page
+	if err := db.Limit(size + 1).Find(&result.Items).Error; err != nil {
+		return Page[T]{}, repo.translate(err)
+	}
+
+	if len(result.Items) > size {
+		result.Items = result.Items[:size]
+		result.HasMore = true
+
+		next, err := encodeCursor(result.Items[size-1].GetID())
+		if err != nil {
+			return Page[T]{}, err
+		}
+		result.NextCursor = next
+	}
+
+	return result, nil
+}
+
+// Helper function normalizePage defaults the page to 1 and the size to DefaultPageSize, clamps the size
+// to MaxPageSize and the page so that its offset cannot overflow.
+func normalizePage(page, size int) (int, int) {
+	if size < 1 {
+		size = DefaultPageSize
+	}
+	if MaxPageSize > 0 && size > MaxPageSize {
+		size = MaxPageSize
+	}
+	page = min(max(page, 1), math.MaxInt/size)
+	return page, size
+}
+
+// Helper function encodeCursor serializes a key into an opaque, url safe cursor.
+func encodeCursor[K any](key K) (string, error) {
+	data, err := sonic.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// Helper function decodeCursor deserializes a cursor created by encodeCursor.
+func decodeCursor[K any](cursor string) (K, error) {
+	var key K
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return key, ErrInvalidCursor
+	}
+	if err := sonic.Unmarshal(data, &key); err != nil {
+		return key, ErrInvalidCursor
+	}
+	return key, nil
+}
@@ -0,0 +1,64 @@
+package stdlib
+
+import (
+	"math"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor, err := encodeCursor(uint(42))
+	assert.NoError(t, err)
+	id, err := decodeCursor[uint](cursor)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(42), id)
+
+	expected := uuid.New()
+	cursor, err = encodeCursor(expected)
+	assert.NoError(t, err)
+	decoded, err := decodeCursor[uuid.UUID](cursor)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, decoded)
+
+	_, err = decodeCursor[uint]("%%not-a-cursor%%")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestFindPageQueries(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := newTestAccountRepository(db)
+
+	page, err := repo.FindPage(3, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, page.Page)
+	assert.Equal(t, 10, page.Size)
+	assert.Contains(t, captured.sql[0], "SELECT count(*) FROM \"test_accounts\"")
+	assert.Contains(t, captured.last(), `ORDER BY "test_accounts"."id" LIMIT 10 OFFSET 20`)
+
+	page, err = repo.FindPage(0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, page.Page)
+	assert.Equal(t, DefaultPageSize, page.Size)
+
+	page, err = repo.FindPage(math.MaxInt, 100000000)
+	assert.NoError(t, err)
+	assert.Equal(t, MaxPageSize, page.Size, "The size should be clamped")
+	assert.Contains(t, captured.last(), "LIMIT 100 OFFSET ")
+	assert.False(t, page.HasMore)
+}
+
+func TestFindAfterQueries(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := newTestAccountRepository(db)
+
+	cursor, _ := encodeCursor(uint(7))
+	_, err := repo.FindAfter(cursor, 5)
+	assert.NoError(t, err)
+	assert.Contains(t, captured.last(), `WHERE "test_accounts"."id" > 7`)
+	assert.Contains(t, captured.last(), `ORDER BY "test_accounts"."id" LIMIT 6`)
+
+	_, err = repo.FindAfter("%%", 5)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
@@ -0,0 +1,61 @@
+package stdlib
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ErrUnknownAssociation is returned when a preload references an association that is not part of the entity schema.
+var ErrUnknownAssociation = errors.New("unknown association")
+
+type preload struct {
+	association string
+	filter      Filter
+}
+
+// Helper function applyPreloadOptions validates the preloads of the query options against the schema
+// and adds them, with their conditions, to the query.
+func applyPreloadOptions(db *gorm.DB, sch *schema.Schema, preloads []preload) (*gorm.DB, error) {
+	for _, p := range preloads {
+		if p.association == clause.Associations {
+			db = db.Preload(p.association)
+			continue
+		}
+
+		associationSchema, err := resolveAssociation(sch, p.association)
+		if err != nil {
+			return nil, err
+		}
+
+		expr, err := p.filter.build(associationSchema)
+		if err != nil {
+			return nil, err
+		}
+		if expr == nil {
+			db = db.Preload(p.association)
+			continue
+		}
+		db = db.Preload(p.association, func(tx *gorm.DB) *gorm.DB {
+			return tx.Clauses(clause.Where{Exprs: []clause.Expression{expr}})
+		})
+	}
+	return db, nil
+}
+
+// Helper function resolveAssociation walks the dot separated association path and returns the schema of the last one.
+func resolveAssociation(sch *schema.Schema, association string) (*schema.Schema, error) {
+	current := sch
+	for _, name := range strings.Split(association, ".") {
+		relationship, ok := current.Relationships.Relations[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownAssociation, association)
+		}
+		current = relationship.FieldSchema
+	}
+	return current, nil
+}
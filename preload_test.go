@@ -0,0 +1,106 @@
+package stdlib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testCustomer struct {
+	ID     uint `gorm:"primaryKey"`
+	Name   string
+	Orders []testOrder
+}
+
+func (c *testCustomer) GetID() uint {
+	return c.ID
+}
+
+type testOrder struct {
+	ID             uint `gorm:"primaryKey"`
+	TestCustomerID uint
+	Status         string
+	Items          []testOrderItem
+}
+
+type testOrderItem struct {
+	ID          uint `gorm:"primaryKey"`
+	TestOrderID uint
+	Quantity    int
+}
+
+type testCustomerRepository struct {
+	AbstractRepository[*testCustomer, uint]
+}
+
+func (r *testCustomerRepository) GetPreloads() []string {
+	return []string{"Orders"}
+}
+
+func newTestCustomerRepository(gormDB *gorm.DB) *testCustomerRepository {
+	repo := &testCustomerRepository{}
+	repo.AbstractRepository = CreateRepository(gormDB, repo)
+	return repo
+}
+
+// Helper: serve a customer as the result of the dry run queries, so the preloads are executed
+// (and captured before the query of the customers)
+func serveCustomer(db *gorm.DB) {
+	_ = db.Callback().Query().Before("gorm:preload").Register("test:customer", func(db *gorm.DB) {
+		switch dest := db.Statement.Dest.(type) {
+		case *[]*testCustomer:
+			*dest = []*testCustomer{{ID: 1}}
+		case **testCustomer:
+			if *dest == nil {
+				*dest = &testCustomer{}
+			}
+			(*dest).ID = 1
+		}
+	})
+}
+
+func TestDefaultPreloads(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	serveCustomer(db)
+	repo := newTestCustomerRepository(db)
+
+	_, err := repo.FindAll()
+	assert.NoError(t, err)
+	assert.Len(t, captured.sql, 2)
+	assert.Contains(t, captured.sql[0], `SELECT * FROM "test_orders" WHERE "test_orders"."test_customer_id" = 1`)
+}
+
+func TestPreloadOptions(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	serveCustomer(db)
+	repo := newTestCustomerRepository(db)
+
+	_, err := repo.FindByID(1, WithPreload("Orders", Eq("status", "active")))
+	assert.NoError(t, err)
+	assert.Len(t, captured.sql, 2, "The conditional preload should replace the default one")
+	assert.Contains(t, captured.sql[0], `SELECT * FROM "test_orders" WHERE "test_orders"."status" = 'active' AND "test_orders"."test_customer_id" = 1`)
+
+	captured.sql = nil
+	_, err = repo.FindAll(WithoutPreloads())
+	assert.NoError(t, err)
+	assert.Len(t, captured.sql, 1, "No association should be preloaded")
+
+	captured.sql = nil
+	_, err = repo.FindWhere(nil, WithoutPreloads(), WithPreload("Orders.Items", Gt("quantity", 1)))
+	assert.NoError(t, err)
+	assert.Len(t, captured.sql, 2, "The nested preload should load its parent association")
+	assert.Contains(t, captured.sql[0], `SELECT * FROM "test_orders" WHERE "test_orders"."test_customer_id" = 1`)
+}
+
+func TestPreloadRejectsUnknownAssociations(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := newTestCustomerRepository(db)
+
+	_, err := repo.FindAll(WithPreload("Invoices"))
+	assert.ErrorIs(t, err, ErrUnknownAssociation)
+
+	_, err = repo.FindAll(WithPreload("Orders", Eq("secret", 1)))
+	assert.ErrorIs(t, err, ErrUnknownColumn)
+	assert.Empty(t, captured.sql)
+}
@@ -0,0 +1,162 @@
+package stdlib
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// GetPrimaryKeys implements AbstractRepository.
+func (repo *abstractRepositoryImpl[T, K]) GetPrimaryKeys() []string {
+	sch, err := repo.schema()
+	if err != nil || len(sch.PrimaryFieldDBNames) == 0 {
+		return []string{"id"}
+	}
+	// the schema is shared by every query, so the caller gets a copy
+	return slices.Clone(sch.PrimaryFieldDBNames)
+}
+
+// Helper function primaryKeys returns the primary key columns of the concrete repository if there is one.
+func (repo *abstractRepositoryImpl[T, K]) primaryKeys() []string {
+	if repo.self == nil {
+		return repo.GetPrimaryKeys()
+	}
+	return repo.self.GetPrimaryKeys()
+}
+
+// Helper function keySorts sorts by the primary key columns.
+func (repo *abstractRepositoryImpl[T, K]) keySorts() []Sort {
+	columns := repo.primaryKeys()
+	sorts := make([]Sort, len(columns))
+	for i, column := range columns {
+		sorts[i] = Asc(column)
+	}
+	return sorts
+}
+
+// Helper function keyValues splits an ID into the values of the primary key columns, in order.
+// A scalar ID is the value of a single primary key column, while a struct ID holds the value of
+// each column in the field with the same name as the field of T (composite primary keys).
+func (repo *abstractRepositoryImpl[T, K]) keyValues(sch *schema.Schema, id K) ([]any, error) {
+	columns := repo.primaryKeys()
+	value := reflect.ValueOf(id)
+
+	if _, isValuer := any(id).(driver.Valuer); isValuer || value.Kind() != reflect.Struct {
+		if len(columns) != 1 {
+			return nil, fmt.Errorf("%w: a composite primary key (%v) needs a struct ID", ErrInvalidKey, columns)
+		}
+		return []any{id}, nil
+	}
+
+	values := make([]any, len(columns))
+	for i, column := range columns {
+		name := column
+		if field := sch.LookUpField(column); field != nil {
+			name = field.Name
+		}
+
+		fieldValue := value.FieldByName(name)
+		if !fieldValue.IsValid() || !fieldValue.CanInterface() {
+			return nil, fmt.Errorf("%w: %s has no exported field %s", ErrInvalidKey, value.Type(), name)
+		}
+		values[i] = fieldValue.Interface()
+	}
+	return values, nil
+}
+
+// Helper function byID adds the condition matching the entity with the ID to the query.
+func (repo *abstractRepositoryImpl[T, K]) byID(db *gorm.DB, id K) (*gorm.DB, error) {
+	sch, err := repo.schema()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err = repo.scope(db, sch)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := repo.keyValues(sch, id)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := repo.primaryKeys()
+	filters := make([]Filter, len(columns))
+	for i, column := range columns {
+		filters[i] = Eq(column, values[i])
+	}
+	return applyFilter(db, sch, And(filters...))
+}
+
+// Helper function byIDs adds the condition matching the entities with any of the IDs to the query.
+func (repo *abstractRepositoryImpl[T, K]) byIDs(db *gorm.DB, ids []K) (*gorm.DB, error) {
+	sch, err := repo.schema()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err = repo.scope(db, sch)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := repo.primaryKeys()
+	keys := make([][]any, len(ids))
+	for i, id := range ids {
+		if keys[i], err = repo.keyValues(sch, id); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(columns) == 1 {
+		values := make([]any, len(keys))
+		for i, key := range keys {
+			values[i] = key[0]
+		}
+		return applyFilter(db, sch, In(columns[0], values...))
+	}
+
+	filters := make([]Filter, len(keys))
+	for i, key := range keys {
+		conditions := make([]Filter, len(columns))
+		for j, column := range columns {
+			conditions[j] = Eq(column, key[j])
+		}
+		filters[i] = And(conditions...)
+	}
+	return applyFilter(db, sch, Or(filters...))
+}
+
+// keysetFilter matches the entities whose primary key is greater than the given one,
+// using a row value comparison for composite primary keys.
+type keysetFilter struct {
+	columns []string
+	values  []any
+}
+
+func (f keysetFilter) build(sch *schema.Schema) (clause.Expression, error) {
+	columns, err := resolveColumns(sch, f.columns)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(columns) == 1 {
+		return clause.Gt{Column: clause.Column{Table: clause.CurrentTable, Name: columns[0]}, Value: f.values[0]}, nil
+	}
+
+	vars := make([]any, 0, len(columns)*2)
+	for _, column := range columns {
+		vars = append(vars, clause.Column{Table: clause.CurrentTable, Name: column})
+	}
+	vars = append(vars, f.values...)
+
+	placeholders := "(?" + strings.Repeat(", ?", len(columns)-1) + ")"
+	return clause.Expr{SQL: placeholders + " > " + placeholders, Vars: vars}, nil
+}
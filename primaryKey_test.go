@@ -0,0 +1,101 @@
+package stdlib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testMembershipKey struct {
+	AccountID uint
+	GroupID   uint
+}
+
+type testMembership struct {
+	AccountID uint `gorm:"primaryKey"`
+	GroupID   uint `gorm:"primaryKey"`
+	Role      string
+}
+
+func (m *testMembership) GetID() testMembershipKey {
+	return testMembershipKey{AccountID: m.AccountID, GroupID: m.GroupID}
+}
+
+type testMembershipRepository struct {
+	AbstractRepository[*testMembership, testMembershipKey]
+}
+
+type testProfile struct {
+	AccountID uint `gorm:"primaryKey"`
+	Nickname  string
+}
+
+func (p *testProfile) GetID() uint {
+	return p.AccountID
+}
+
+func TestPrimaryKeyDerivedFromSchema(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := &abstractRepositoryImpl[*testProfile, uint]{gorm: db}
+
+	assert.Equal(t, []string{"account_id"}, repo.GetPrimaryKeys())
+
+	repo.GetPrimaryKeys()[0] = "nickname"
+	assert.Equal(t, []string{"account_id"}, repo.GetPrimaryKeys(), "The caller should not modify the cached schema")
+
+	_, _ = repo.FindByID(5)
+	assert.Contains(t, captured.last(), `WHERE "test_profiles"."account_id" = 5`)
+}
+
+func TestCompositePrimaryKey(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := &testMembershipRepository{}
+	repo.AbstractRepository = CreateRepository(db, repo)
+
+	_, _ = repo.FindByID(testMembershipKey{AccountID: 1, GroupID: 2})
+	assert.Contains(t, captured.last(), `WHERE "test_memberships"."account_id" = 1 AND "test_memberships"."group_id" = 2`)
+
+	_ = repo.UpdateSpecific(nil, testMembershipKey{AccountID: 1, GroupID: 2}, map[string]interface{}{"role": "admin"})
+	assert.Contains(t, captured.sql[1], `WHERE "test_memberships"."account_id" = 1 AND "test_memberships"."group_id" = 2`)
+
+	cursor, _ := encodeCursor(testMembershipKey{AccountID: 1, GroupID: 2})
+	_, err := repo.FindAfter(cursor, 10)
+	assert.NoError(t, err)
+	assert.Contains(t, captured.last(), `WHERE ("test_memberships"."account_id", "test_memberships"."group_id") > (1, 2)`)
+	assert.Contains(t, captured.last(), `ORDER BY "test_memberships"."account_id","test_memberships"."group_id"`)
+}
+
+type testGroupMember struct {
+	GroupID  uint `gorm:"primaryKey"`
+	MemberID uint `gorm:"primaryKey"`
+}
+
+func (m *testGroupMember) GetID() uint {
+	return m.GroupID
+}
+
+func TestCompositePrimaryKeyNeedsStructID(t *testing.T) {
+	db, _ := newDryRunDB(t)
+	repo := &abstractRepositoryImpl[*testGroupMember, uint]{gorm: db}
+
+	_, err := repo.FindByID(1)
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+type testProfileRepository struct {
+	AbstractRepository[*testProfile, uint]
+}
+
+func (r *testProfileRepository) GetPrimaryKeys() []string {
+	return []string{"nickname"}
+}
+
+func TestPrimaryKeyOverride(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := &testProfileRepository{}
+	repo.AbstractRepository = CreateRepository[*testProfile, uint](db, repo)
+
+	_ = repo.Delete(db.Session(&gorm.Session{}), 5)
+	assert.Contains(t, captured.sql[0], `WHERE "test_profiles"."nickname" = 5`)
+}
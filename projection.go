@@ -0,0 +1,71 @@
+package stdlib
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Projector is implemented by every AbstractRepository, it is what FindAllAs needs.
+type Projector interface {
+	FindInto(dest any, filter Filter, opts ...QueryOption) error
+}
+
+// FindAllAs retrieves the entities matching the filter as DTO values, only fetching the columns
+// of the DTO fields (or the ones of the Select option). An empty result is an empty slice.
+//
+//	type AccountSummary struct {
+//		ID       uint
+//		Username string
+//	}
+//
+//	summaries, err := stdlib.FindAllAs[AccountSummary](repo, stdlib.Eq("status", "active"))
+func FindAllAs[DTO any](repo Projector, filter Filter, opts ...QueryOption) ([]DTO, error) {
+	dtos := []DTO{}
+	if err := repo.FindInto(&dtos, filter, opts...); err != nil {
+		return nil, err
+	}
+	return dtos, nil
+}
+
+// FindInto implements AbstractRepository.
+func (repo *abstractRepositoryImpl[T, K]) FindInto(dest any, filter Filter, opts ...QueryOption) error {
+	options := newQueryOptions(opts)
+	options.preloads, options.withoutDefaultPreloads = nil, true
+
+	if len(options.selects) == 0 {
+		stmt := &gorm.Statement{DB: repo.gorm}
+		if err := stmt.Parse(dest); err != nil {
+			return err
+		}
+		options.selects = stmt.Schema.DBNames
+	}
+
+	db, err := repo.query(filter, options)
+	if err != nil {
+		return err
+	}
+
+	if err := db.Model(new(T)).Find(dest).Error; err != nil {
+		return repo.translate(err)
+	}
+	return nil
+}
+
+// Helper function applySelect resolves the selected columns against the schema and restricts the query to them.
+func applySelect(db *gorm.DB, sch *schema.Schema, names []string) (*gorm.DB, error) {
+	if len(names) == 0 {
+		return db, nil
+	}
+
+	columns, err := resolveColumns(sch, names)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make([]clause.Column, 0, len(columns))
+	for _, column := range columns {
+		selected = append(selected, clause.Column{Table: clause.CurrentTable, Name: column})
+	}
+	return db.Clauses(clause.Select{Columns: selected}), nil
+}
@@ -0,0 +1,48 @@
+package stdlib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testAccountSummary struct {
+	ID       uint
+	Username string
+}
+
+type testAccountSecret struct {
+	ID       uint
+	Password string
+}
+
+func TestSelectOption(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := newTestAccountRepository(db)
+
+	_, err := repo.FindWhere(Eq("username", "john"), Select("ID", "email"))
+	assert.NoError(t, err)
+	assert.Contains(t, captured.last(), `SELECT "test_accounts"."id","test_accounts"."email" FROM "test_accounts" WHERE "test_accounts"."username" = 'john'`)
+
+	_, err = repo.FindAll(Select("password"))
+	assert.ErrorIs(t, err, ErrUnknownColumn)
+}
+
+func TestFindAllAs(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := newTestAccountRepository(db)
+
+	summaries, err := FindAllAs[testAccountSummary](repo, nil, OrderBy(Asc("username")))
+	assert.NoError(t, err)
+	assert.NotNil(t, summaries, "An empty result should be an empty slice")
+	assert.Contains(t, captured.last(), `SELECT "test_accounts"."id","test_accounts"."username" FROM "test_accounts" WHERE "test_accounts"."deleted_at" IS NULL ORDER BY "test_accounts"."username"`)
+
+	_, err = FindAllAs[testAccountSummary](repo, nil, Select("username"))
+	assert.NoError(t, err)
+	assert.Contains(t, captured.last(), `SELECT "test_accounts"."username" FROM "test_accounts"`)
+
+	count := len(captured.sql)
+	_, err = FindAllAs[testAccountSecret](repo, nil)
+	assert.ErrorIs(t, err, ErrUnknownColumn, "DTO fields should be columns of the entity")
+	assert.Len(t, captured.sql, count)
+}
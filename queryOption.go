@@ -0,0 +1,67 @@
+package stdlib
+
+// QueryOption customizes a single call of the repository finders (e.g. ordering, preloads or projection).
+// Options are applied in order, so the last one wins when two of them conflict.
+type QueryOption func(*queryOptions)
+
+type queryOptions struct {
+	sorts                  []Sort
+	selects                []string
+	preloads               []preload
+	withoutDefaultPreloads bool
+}
+
+// OrderBy sorts the results by the given columns, in order.
+// The columns are validated against the GORM schema of the entity.
+//
+//	accounts, err := repo.FindAll(stdlib.OrderBy(stdlib.Desc("created_at"), stdlib.Asc("username")))
+func OrderBy(sorts ...Sort) QueryOption {
+	return func(o *queryOptions) {
+		o.sorts = append(o.sorts, sorts...)
+	}
+}
+
+// Select only fetches the given columns, the other fields of the entities are left zero valued.
+// The columns are validated against the GORM schema of the entity; keep the primary key
+// (and the foreign keys) selected when preloading associations.
+//
+//	accounts, err := repo.FindAll(stdlib.Select("id", "username"))
+func Select(columns ...string) QueryOption {
+	return func(o *queryOptions) {
+		o.selects = append(o.selects, columns...)
+	}
+}
+
+// Helper function newQueryOptions applies the options over the defaults.
+func newQueryOptions(opts []QueryOption) *queryOptions {
+	options := &queryOptions{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(options)
+		}
+	}
+	return options
+}
+
+// WithPreload preloads the association, in addition to the default preloads of the repository (see GetPreloads).
+// Nested associations are separated by dots (e.g. "Orders.Items"), and the optional filters (combined with And)
+// restrict the associated entities that are loaded, resolved against the schema of the last association.
+// Preloading an association that is already preloaded replaces its conditions.
+//
+//	customer, err := repo.FindByID(id, stdlib.WithPreload("Orders", stdlib.Eq("status", "active")))
+func WithPreload(association string, filters ...Filter) QueryOption {
+	return func(o *queryOptions) {
+		o.preloads = append(o.preloads, preload{association: association, filter: And(filters...)})
+	}
+}
+
+// WithoutPreloads drops the default preloads of the repository and the ones added by previous options,
+// it can be followed by WithPreload to replace them.
+//
+//	accounts, err := repo.FindAll(stdlib.WithoutPreloads(), stdlib.WithPreload("Roles"))
+func WithoutPreloads() QueryOption {
+	return func(o *queryOptions) {
+		o.preloads = nil
+		o.withoutDefaultPreloads = true
+	}
+}
@@ -0,0 +1,128 @@
+package stdlib
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Errors returned by the repositories, the driver errors are translated into them so
+// the callers can check them with errors.Is without depending on gorm or on the driver.
+// The original driver error is still wrapped and can be retrieved with errors.As.
+var (
+	// ErrRecordNotFound is returned when the entity does not exist, by the finders returning
+	// a single entity and by the writes identified by ID (Update, UpdateSpecific, Delete and Restore).
+	// It is the same error as gorm.ErrRecordNotFound, so both can be used with errors.Is.
+	ErrRecordNotFound = gorm.ErrRecordNotFound
+
+	// ErrRecordConflict is returned when a write conflicts with the stored data.
+	// ErrDuplicateKey and ErrStaleEntity are conflicts too, so errors.Is matches them with it.
+	ErrRecordConflict = errors.New("record conflict")
+
+	// ErrDuplicateKey is returned when a write violates a unique constraint.
+	ErrDuplicateKey = fmt.Errorf("%w: duplicate key", ErrRecordConflict)
+
+	// ErrStaleEntity is returned when an update of a Versioned entity does not match
+	// the stored version, meaning that the entity was modified concurrently.
+	ErrStaleEntity = fmt.Errorf("%w: stale entity, it was modified concurrently", ErrRecordConflict)
+
+	// ErrSoftDeleteUnsupported is returned by the soft delete operations (e.g. Restore)
+	// when the entity has no gorm.DeletedAt field.
+	ErrSoftDeleteUnsupported = errors.New("soft delete unsupported: the entity has no gorm.DeletedAt field")
+
+	// ErrInvalidKey is returned when an ID cannot be mapped to the primary key columns of the entity.
+	ErrInvalidKey = errors.New("invalid primary key")
+
+	// ErrForeignKey is returned when a write violates a foreign key constraint,
+	// either referencing a missing entity or deleting a referenced one.
+	ErrForeignKey = errors.New("foreign key violation")
+)
+
+// ConstraintError is a unique or foreign key violation with the details reported by the driver,
+// it matches its Kind (ErrDuplicateKey or ErrForeignKey) and the original driver error with errors.Is/As.
+//
+//	var constraintErr *stdlib.ConstraintError
+//	if errors.As(err, &constraintErr) && errors.Is(err, stdlib.ErrDuplicateKey) {
+//		return stdlib.PersonalizedErr(c, "already taken: "+strings.Join(constraintErr.Columns, ", "), fiber.StatusConflict)
+//	}
+type ConstraintError struct {
+	// Kind is ErrDuplicateKey or ErrForeignKey.
+	Kind error
+	// Constraint is the name of the violated constraint or index, if reported.
+	Constraint string
+	// Table is the table of the constraint, if reported.
+	Table string
+	// Columns are the columns of the constraint, if they could be determined.
+	Columns []string
+	// Err is the original driver error.
+	Err error
+}
+
+func (e *ConstraintError) Error() string {
+	msg := e.Kind.Error()
+	if e.Constraint != "" {
+		msg += " on " + e.Constraint
+	}
+	if len(e.Columns) > 0 {
+		msg += " (" + strings.Join(e.Columns, ", ") + ")"
+	}
+	return msg + ": " + e.Err.Error()
+}
+
+func (e *ConstraintError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// PanicError is a panic of a transaction function, returned once rolled back with the WithPanicAsError option.
+// It matches the panic value with errors.Is/As if it is an error. The stack trace is only kept in Stack,
+// so the message can be logged or returned to a client.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the goroutine when it panicked.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic in transaction: %v", e.Value)
+}
+
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// Helper function constraintColumns resolves the columns of a constraint or index of the schema by its name.
+func constraintColumns(sch *schema.Schema, name string) []string {
+	columns := []string{}
+	if name == "" {
+		return columns
+	}
+
+	if index, ok := sch.ParseIndexes()[name]; ok {
+		for _, option := range index.Fields {
+			if option.Field != nil {
+				columns = append(columns, option.DBName)
+			}
+		}
+		return columns
+	}
+
+	if unique, ok := sch.ParseUniqueConstraints()[name]; ok {
+		return append(columns, unique.Field.DBName)
+	}
+
+	for _, relationship := range sch.Relationships.Relations {
+		if constraint := relationship.ParseConstraint(); constraint != nil && constraint.Name == name {
+			for _, field := range constraint.ForeignKeys {
+				columns = append(columns, field.DBName)
+			}
+			return columns
+		}
+	}
+
+	return columns
+}
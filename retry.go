@@ -0,0 +1,66 @@
+package stdlib
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// Defaults of RetryPolicy.
+const (
+	DefaultRetryAttempts  int           = 3
+	DefaultRetryBaseDelay time.Duration = 50 * time.Millisecond
+	DefaultRetryMaxDelay  time.Duration = 2 * time.Second
+)
+
+// RetryPolicy configures the retries of a transaction, see WithRetry.
+// The zero value retries the serialization failures and deadlocks up to DefaultRetryAttempts times.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times the transaction runs at most, DefaultRetryAttempts if it is not positive.
+	MaxAttempts int
+
+	// BaseDelay is the backoff before the first retry, doubled on every retry up to MaxDelay,
+	// DefaultRetryBaseDelay and DefaultRetryMaxDelay if they are not positive. The actual wait is
+	// a random duration up to the backoff (full jitter), so the competing transactions spread out.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	// Retryable classifies the errors worth retrying, IsRetryable if it is nil.
+	Retryable func(err error) bool
+}
+
+// Helper function do runs fn until it succeeds, fails with an error that is not retryable,
+// runs out of attempts or ctx is done while waiting; the last error is returned.
+func (p RetryPolicy) do(ctx context.Context, fn func() error) error {
+	attempts, backoff, maxDelay, retryable := p.MaxAttempts, p.BaseDelay, p.MaxDelay, p.Retryable
+	if attempts < 1 {
+		attempts = DefaultRetryAttempts
+	}
+	if backoff <= 0 {
+		backoff = DefaultRetryBaseDelay
+	}
+	if maxDelay <= 0 {
+		maxDelay = DefaultRetryMaxDelay
+	}
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= attempts || !retryable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(rand.N(min(backoff, maxDelay)) + 1)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		if backoff < maxDelay {
+			backoff *= 2
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package stdlib
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	deadlock := &mysql.MySQLError{Number: 1213}
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Microsecond}
+
+	calls := 0
+	err := policy.do(context.Background(), func() error {
+		calls++
+		if calls < 2 {
+			return deadlock
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls, "A retryable failure should be retried")
+
+	calls = 0
+	err = policy.do(context.Background(), func() error {
+		calls++
+		return deadlock
+	})
+	assert.ErrorIs(t, err, deadlock)
+	assert.Equal(t, 3, calls, "The retries should stop after MaxAttempts")
+
+	calls = 0
+	errInvalid := errors.New("invalid transfer")
+	err = policy.do(context.Background(), func() error {
+		calls++
+		return errInvalid
+	})
+	assert.ErrorIs(t, err, errInvalid)
+	assert.Equal(t, 1, calls, "Other errors should not be retried")
+
+	calls = 0
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = RetryPolicy{BaseDelay: time.Hour}.do(ctx, func() error {
+		calls++
+		return deadlock
+	})
+	assert.ErrorIs(t, err, deadlock)
+	assert.Equal(t, 1, calls, "A done context should stop the retries")
+}
+
+func TestNestedTransactionIsNotRetried(t *testing.T) {
+	db, _ := newDryRunDB(t)
+	tx, _ := newDryRunTx(db)
+
+	calls := 0
+	err := NewTransactionalRepository(db).ExecuteInNestedTransaction(tx, func(tx *gorm.DB) error {
+		calls++
+		return &pgconn.PgError{Code: "40001"}
+	}, WithRetry(RetryPolicy{BaseDelay: time.Microsecond}))
+	assert.True(t, IsRetryable(err))
+	assert.Equal(t, 1, calls, "Only the outer transaction can be retried")
+}
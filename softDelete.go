@@ -0,0 +1,84 @@
+package stdlib
+
+import (
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+
+// FindDeleted implements AbstractRepository.
+func (repo *abstractRepositoryImpl[T, K]) FindDeleted(opts ...QueryOption) ([]T, error) {
+	var entities []T
+
+	deletedAt, err := repo.deletedAtColumn()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := repo.query(IsNotNull(deletedAt), newQueryOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Unscoped().Find(&entities).Error; err != nil {
+		return entities, repo.translate(err)
+	}
+
+	return entities, nil
+}
+
+// HardDelete implements AbstractRepository.
+func (repo *abstractRepositoryImpl[T, K]) HardDelete(tx *gorm.DB, id K) error {
+	return repo.write(tx, &Change[T, K]{Operation: OperationHardDelete, ID: id}, func(tx *gorm.DB) error {
+		entity := createInstance[T]()
+		db, err := repo.byID(repo.transCheck(tx).Unscoped(), id)
+		if err != nil {
+			return err
+		}
+
+		result := db.Delete(entity)
+		if result.Error != nil {
+			return repo.translate(result.Error)
+		}
+		return repo.checkAffected(tx, id, result.RowsAffected, true)
+	})
+}
+
+// PurgeDeletedBefore implements AbstractRepository.
+func (repo *abstractRepositoryImpl[T, K]) PurgeDeletedBefore(tx *gorm.DB, before time.Time) (int64, error) {
+	deletedAt, err := repo.deletedAtColumn()
+	if err != nil {
+		return 0, err
+	}
+
+	db, err := repo.model(tx, Lt(deletedAt, before))
+	if err != nil {
+		return 0, err
+	}
+
+	result := db.Unscoped().Delete(new(T))
+	if result.Error != nil {
+		return 0, repo.translate(result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// Helper function deletedAtColumn returns the gorm.DeletedAt column of T,
+// or ErrSoftDeleteUnsupported if there is none.
+func (repo *abstractRepositoryImpl[T, K]) deletedAtColumn() (string, error) {
+	sch, err := repo.schema()
+	if err != nil {
+		return "", err
+	}
+
+	for _, field := range sch.Fields {
+		if field.DBName != "" && field.FieldType == deletedAtType {
+			return field.DBName, nil
+		}
+	}
+	return "", ErrSoftDeleteUnsupported
+}
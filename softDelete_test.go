@@ -0,0 +1,37 @@
+package stdlib
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindDeletedAndPurge(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := newTestAccountRepository(db)
+
+	_, err := repo.FindDeleted(OrderBy(Desc("deleted_at")))
+	assert.NoError(t, err)
+	assert.Contains(t, captured.last(), `SELECT * FROM "test_accounts" WHERE "test_accounts"."deleted_at" IS NOT NULL ORDER BY "test_accounts"."deleted_at" DESC`)
+
+	_, err = repo.PurgeDeletedBefore(nil, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Contains(t, captured.last(), `DELETE FROM "test_accounts" WHERE "test_accounts"."deleted_at" < '2024-01-01 00:00:00'`)
+
+	err = repo.HardDelete(nil, 1)
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+	assert.Contains(t, captured.sql[2], `DELETE FROM "test_accounts" WHERE "test_accounts"."id" = 1`)
+}
+
+func TestSoftDeleteUnsupported(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := newTestDocumentRepository(db)
+
+	assert.ErrorIs(t, repo.Restore(nil, 1), ErrSoftDeleteUnsupported)
+	_, err := repo.FindDeleted()
+	assert.ErrorIs(t, err, ErrSoftDeleteUnsupported)
+	_, err = repo.PurgeDeletedBefore(nil, time.Now())
+	assert.ErrorIs(t, err, ErrSoftDeleteUnsupported)
+	assert.Empty(t, captured.sql)
+}
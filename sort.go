@@ -0,0 +1,134 @@
+package stdlib
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// NullsOrder defines where NULL values are placed when sorting.
+type NullsOrder int
+
+const (
+	// NullsDefault keeps the database default placement.
+	NullsDefault NullsOrder = iota
+	// NullsFirst places NULL values before any other value.
+	NullsFirst
+	// NullsLast places NULL values after any other value.
+	NullsLast
+)
+
+// Sort represents the ordering of a single column.
+type Sort struct {
+	Column string
+	Desc   bool
+	Nulls  NullsOrder
+}
+
+// Asc sorts by column in ascending order.
+func Asc(column string) Sort {
+	return Sort{Column: column}
+}
+
+// Desc sorts by column in descending order.
+func Desc(column string) Sort {
+	return Sort{Column: column, Desc: true}
+}
+
+// NullsFirst returns a copy of the sort placing NULL values first.
+func (s Sort) NullsFirst() Sort {
+	s.Nulls = NullsFirst
+	return s
+}
+
+// NullsLast returns a copy of the sort placing NULL values last.
+func (s Sort) NullsLast() Sort {
+	s.Nulls = NullsLast
+	return s
+}
+
+// ParseSort parses a sort expression like "-created_at,name" into sorts.
+// A leading '-' means descending and an optional leading '+' means ascending.
+// If allowed is not empty, only those columns can be used, otherwise ErrUnknownColumn is returned.
+func ParseSort(raw string, allowed ...string) ([]Sort, error) {
+	var sorts []Sort
+
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		sort := Sort{}
+		switch part[0] {
+		case '-':
+			sort.Desc = true
+			part = part[1:]
+		case '+':
+			part = part[1:]
+		}
+
+		if part == "" || (len(allowed) > 0 && !slices.Contains(allowed, part)) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownColumn, part)
+		}
+		sort.Column = part
+		sorts = append(sorts, sort)
+	}
+
+	return sorts, nil
+}
+
+// ParseSortQuery parses the `sort` query parameter of the request (e.g. ?sort=-created_at,name).
+// See ParseSort for the syntax and the allowed columns.
+func ParseSortQuery(c fiber.Ctx, allowed ...string) ([]Sort, error) {
+	return ParseSort(c.Query("sort"), allowed...)
+}
+
+// Helper function applySorts validates the sorts against the schema and adds them to the query.
+func applySorts(db *gorm.DB, sch *schema.Schema, sorts []Sort) (*gorm.DB, error) {
+	if len(sorts) == 0 {
+		return db, nil
+	}
+
+	columns := make([]clause.OrderByColumn, 0, len(sorts))
+	for _, sort := range sorts {
+		name, err := resolveColumn(sch, sort.Column)
+		if err != nil {
+			return nil, err
+		}
+
+		column := db.Statement.Quote(clause.Column{Table: sch.Table, Name: name})
+		columns = append(columns, clause.OrderByColumn{
+			Column: clause.Column{Name: orderBySQL(db.Dialector.Name(), column, sort), Raw: true},
+		})
+	}
+
+	return db.Clauses(clause.OrderBy{Columns: columns}), nil
+}
+
+// Helper function orderBySQL writes the ORDER BY item of a sort, MySQL/MariaDB does not
+// support NULLS FIRST / NULLS LAST so it is emulated ordering by `column IS NULL` first.
+func orderBySQL(dialect, column string, sort Sort) string {
+	sql := column
+	if sort.Desc {
+		sql += " DESC"
+	}
+
+	switch {
+	case sort.Nulls == NullsDefault:
+		return sql
+	case dialect == mysqlDialect && sort.Nulls == NullsFirst:
+		return column + " IS NULL DESC, " + sql
+	case dialect == mysqlDialect:
+		return column + " IS NULL, " + sql
+	case sort.Nulls == NullsFirst:
+		return sql + " NULLS FIRST"
+	default:
+		return sql + " NULLS LAST"
+	}
+}
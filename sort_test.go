@@ -0,0 +1,49 @@
+package stdlib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSort(t *testing.T) {
+	sorts, err := ParseSort("-created_at, +username,email")
+	assert.NoError(t, err)
+	assert.Equal(t, []Sort{Desc("created_at"), Asc("username"), Asc("email")}, sorts)
+
+	sorts, err = ParseSort("")
+	assert.NoError(t, err)
+	assert.Empty(t, sorts)
+
+	_, err = ParseSort("-password", "username", "created_at")
+	assert.ErrorIs(t, err, ErrUnknownColumn)
+
+	_, err = ParseSort("-")
+	assert.ErrorIs(t, err, ErrUnknownColumn)
+}
+
+func TestFindAllOrderBy(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := newTestAccountRepository(db)
+
+	_, _ = repo.FindAll(OrderBy(Desc("created_at").NullsLast(), Asc("Username")))
+	assert.Contains(t, captured.last(), `ORDER BY "test_accounts"."created_at" DESC NULLS LAST,"test_accounts"."username"`)
+
+	_, err := repo.FindAll(OrderBy(Asc("password")))
+	assert.ErrorIs(t, err, ErrUnknownColumn)
+}
+
+func TestFindPageOrderByKeepsIDTiebreaker(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := newTestAccountRepository(db)
+
+	_, err := repo.FindPage(1, 10, OrderBy(Desc("username")))
+	assert.NoError(t, err)
+	assert.Contains(t, captured.last(), `ORDER BY "test_accounts"."username" DESC,"test_accounts"."id" LIMIT 10`)
+}
+
+func TestOrderBySQLEmulatesNullsOnMySQL(t *testing.T) {
+	assert.Equal(t, "`a` IS NULL DESC, `a` DESC", orderBySQL("mysql", "`a`", Desc("a").NullsFirst()))
+	assert.Equal(t, "`a` IS NULL, `a`", orderBySQL("mysql", "`a`", Asc("a").NullsLast()))
+	assert.Equal(t, `"a" NULLS FIRST`, orderBySQL("postgres", `"a"`, Asc("a").NullsFirst()))
+}
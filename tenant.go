@@ -0,0 +1,186 @@
+package stdlib
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// DefaultTenantColumn is the tenant column used by WithTenantScope when none is given.
+const DefaultTenantColumn string = "tenant_id"
+
+// ErrMissingTenant is returned by a tenant scoped repository when the context carries no tenant,
+// so a forgotten tenant fails instead of reading or writing the data of every tenant.
+var ErrMissingTenant = errors.New("missing tenant in context")
+
+// ErrTenantUpsertUnsupported is returned by the upserts of a tenant scoped repository on MySQL/MariaDB,
+// which cannot restrict the update of the conflicting row to the tenant of the context.
+var ErrTenantUpsertUnsupported = errors.New("tenant scoped upserts are unsupported on MySQL/MariaDB")
+
+type tenantKey struct{}
+
+type tenantScopeDisabledKey struct{}
+
+// WithTenant returns a copy of ctx carrying the tenant applied by the tenant scoped repositories
+// (see WithTenantScope). Bind it to the repository with WithContext.
+func WithTenant(ctx context.Context, tenant any) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant of the context set with WithTenant, if any.
+func TenantFromContext(ctx context.Context) (any, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	tenant := ctx.Value(tenantKey{})
+	return tenant, tenant != nil
+}
+
+// WithoutTenantScope returns a copy of ctx that disables the tenant scope of the repositories,
+// for the admin queries that must see (or write) the data of every tenant. Use it explicitly and sparingly.
+func WithoutTenantScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, tenantScopeDisabledKey{}, true)
+}
+
+// WithTenantScope makes the repository tenant scoped: the tenant of the context (see WithTenant) is applied
+// to every read, Update, Delete and Restore through the column (DefaultTenantColumn if it is empty), and it is
+// stamped on the entities written by Create, Update, CreateMany and the upserts. UpdateSpecific, UpdateMany and
+// the upserts never overwrite the tenant column. Without a tenant in the context ErrMissingTenant is returned,
+// unless the scope is disabled with WithoutTenantScope.
+//
+// An upsert conflicting with the row of another tenant returns ErrRecordConflict. On MySQL/MariaDB
+// the upserts cannot check the tenant of the conflicting row, so they return ErrTenantUpsertUnsupported.
+//
+//	repo.AbstractRepository = stdlib.CreateRepository(gormDB, repo, stdlib.WithTenantScope[*models.Invoice, uint](""))
+//	...
+//	invoices, err := repo.WithContext(stdlib.WithTenant(ctx, tenantID)).FindAll()
+func WithTenantScope[T Identifiable[K], K ID](column string) RepositoryOption[T, K] {
+	if column == "" {
+		column = DefaultTenantColumn
+	}
+	return func(repo *abstractRepositoryImpl[T, K]) {
+		repo.tenantColumn = column
+	}
+}
+
+// Helper function tenant returns the tenant of the context of the query, and whether the tenant scope applies.
+func (repo *abstractRepositoryImpl[T, K]) tenant(db *gorm.DB) (any, bool, error) {
+	if repo.tenantColumn == "" {
+		return nil, false, nil
+	}
+
+	ctx := db.Statement.Context
+	if disabled, _ := ctx.Value(tenantScopeDisabledKey{}).(bool); disabled {
+		return nil, false, nil
+	}
+
+	tenant, ok := TenantFromContext(ctx)
+	if !ok {
+		return nil, false, ErrMissingTenant
+	}
+	return tenant, true, nil
+}
+
+// Helper function scope restricts the query to the tenant of its context if the repository is tenant scoped.
+func (repo *abstractRepositoryImpl[T, K]) scope(db *gorm.DB, sch *schema.Schema) (*gorm.DB, error) {
+	tenant, ok, err := repo.tenant(db)
+	if !ok || err != nil {
+		return db, err
+	}
+	return applyFilter(db, sch, Eq(repo.tenantColumn, tenant))
+}
+
+// Helper function stampTenant sets the tenant of the context of the query on the entities.
+func (repo *abstractRepositoryImpl[T, K]) stampTenant(db *gorm.DB, entities ...*T) error {
+	tenant, ok, err := repo.tenant(db)
+	if !ok || err != nil {
+		return err
+	}
+
+	field, err := repo.tenantField()
+	if err != nil {
+		return err
+	}
+
+	for _, entity := range entities {
+		value := reflect.ValueOf(entity)
+		for value.Kind() == reflect.Pointer && !value.IsNil() {
+			value = value.Elem()
+		}
+		if value.Kind() != reflect.Struct {
+			continue
+		}
+		if err := field.Set(db.Statement.Context, value, tenant); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Helper function stampTenants sets the tenant of the context of the query on every entity of the slice.
+func (repo *abstractRepositoryImpl[T, K]) stampTenants(db *gorm.DB, entities []T) error {
+	pointers := make([]*T, len(entities))
+	for i := range entities {
+		pointers[i] = &entities[i]
+	}
+	return repo.stampTenant(db, pointers...)
+}
+
+// Helper function withoutTenantField removes the tenant column (by column or field name) from the fields.
+func (repo *abstractRepositoryImpl[T, K]) withoutTenantField(db *gorm.DB, fields map[string]interface{}) error {
+	if _, ok, err := repo.tenant(db); !ok || err != nil {
+		return err
+	}
+
+	field, err := repo.tenantField()
+	if err != nil {
+		return err
+	}
+	delete(fields, field.DBName)
+	delete(fields, field.Name)
+	return nil
+}
+
+// Helper function tenantGuard restricts the update of an upsert to the rows of the tenant of the context,
+// reporting whether it applies. MySQL/MariaDB do not support a condition on the update, so it fails there.
+func (repo *abstractRepositoryImpl[T, K]) tenantGuard(db *gorm.DB, onConflict clause.OnConflict) (clause.OnConflict, bool, error) {
+	tenant, ok, err := repo.tenant(db)
+	if !ok || err != nil {
+		return onConflict, false, err
+	}
+	if db.Dialector.Name() == mysqlDialect {
+		return onConflict, false, ErrTenantUpsertUnsupported
+	}
+
+	field, err := repo.tenantField()
+	if err != nil {
+		return onConflict, false, err
+	}
+
+	sch, err := repo.schema()
+	if err != nil {
+		return onConflict, false, err
+	}
+	onConflict.Where = clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: sch.Table, Name: field.DBName}, Value: tenant},
+	}}
+	return onConflict, true, nil
+}
+
+// Helper function tenantField returns the schema field of the tenant column.
+func (repo *abstractRepositoryImpl[T, K]) tenantField() (*schema.Field, error) {
+	sch, err := repo.schema()
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := resolveColumn(sch, repo.tenantColumn)
+	if err != nil {
+		return nil, err
+	}
+	return sch.LookUpField(name), nil
+}
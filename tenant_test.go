@@ -0,0 +1,100 @@
+package stdlib
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testInvoice struct {
+	ID       uint `gorm:"primaryKey"`
+	TenantID string
+	Number   string
+}
+
+func (i *testInvoice) GetID() uint {
+	return i.ID
+}
+
+type testInvoiceRepository struct {
+	AbstractRepository[*testInvoice, uint]
+}
+
+func newTestInvoiceRepository(gormDB *gorm.DB) *testInvoiceRepository {
+	repo := &testInvoiceRepository{}
+	repo.AbstractRepository = CreateRepository(gormDB, repo, WithTenantScope[*testInvoice, uint](""))
+	return repo
+}
+
+func TestTenantScopeFailsClosed(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := newTestInvoiceRepository(db)
+
+	_, err := repo.FindAll()
+	assert.ErrorIs(t, err, ErrMissingTenant)
+	_, err = repo.Create(nil, &testInvoice{Number: "F-1"})
+	assert.ErrorIs(t, err, ErrMissingTenant)
+	assert.ErrorIs(t, repo.Delete(nil, 1), ErrMissingTenant)
+	assert.Empty(t, captured.sql, "Nothing should reach the database without a tenant")
+}
+
+func TestTenantScopeAppliesToReadsAndWrites(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := newTestInvoiceRepository(db).WithContext(WithTenant(context.Background(), "acme"))
+
+	_, err := repo.FindWhere(Eq("number", "F-1"))
+	assert.NoError(t, err)
+	assert.Contains(t, captured.last(), `SELECT * FROM "test_invoices" WHERE "test_invoices"."number" = 'F-1' AND "test_invoices"."tenant_id" = 'acme'`)
+
+	_, err = repo.FindPage(1, 10)
+	assert.NoError(t, err)
+	assert.Contains(t, captured.sql[len(captured.sql)-2], `SELECT count(*) FROM "test_invoices" WHERE "test_invoices"."tenant_id" = 'acme'`)
+
+	created, err := repo.Create(nil, &testInvoice{TenantID: "other", Number: "F-2"})
+	assert.NoError(t, err)
+	assert.Equal(t, "acme", created.TenantID, "The tenant of the context should be stamped")
+	assert.Contains(t, captured.last(), `INSERT INTO "test_invoices" ("tenant_id","number") VALUES ('acme','F-2')`)
+
+	assert.ErrorIs(t, repo.UpdateSpecific(nil, 1, map[string]interface{}{"number": "F-3", "TenantID": "other"}), ErrRecordNotFound)
+	assert.Contains(t, captured.sql[len(captured.sql)-2], `UPDATE "test_invoices" SET "number"='F-3' WHERE "test_invoices"."tenant_id" = 'acme' AND "test_invoices"."id" = 1`)
+
+	_, err = repo.UpdateMany(nil, Eq("number", "F-3"), map[string]interface{}{"number": "F-5", "tenant_id": "other"})
+	assert.NoError(t, err)
+	assert.Contains(t, captured.last(), `UPDATE "test_invoices" SET "number"='F-5' WHERE "test_invoices"."tenant_id" = 'acme' AND "test_invoices"."number" = 'F-3'`)
+
+	// a dry run affects no row, as when the conflicting row belongs to another tenant
+	_, err = repo.Upsert(nil, &testInvoice{ID: 1, Number: "F-4"}, UpsertOptions{UpdateColumns: []string{"number", "tenant_id"}})
+	assert.ErrorIs(t, err, ErrRecordConflict)
+	assert.Contains(t, captured.last(), `ON CONFLICT ("id") DO UPDATE SET "number"="excluded"."number" WHERE "test_invoices"."tenant_id" = 'acme'`)
+
+	captured.sql = nil
+	_, err = repo.Upsert(nil, &testInvoice{ID: 1, Number: "F-4"}, UpsertOptions{UpdateColumns: []string{"tenant_id"}})
+	assert.ErrorContains(t, err, "no column to update on conflict")
+	assert.NotErrorIs(t, err, ErrRecordConflict)
+	assert.Empty(t, captured.sql, "An upsert without columns to update should be rejected")
+
+	_, err = repo.UpsertMany(nil, []*testInvoice{{ID: 1, Number: "F-4"}}, UpsertOptions{})
+	assert.ErrorIs(t, err, ErrRecordConflict)
+	assert.Contains(t, captured.last(), `ON CONFLICT ("id") DO UPDATE SET "number"="excluded"."number" WHERE "test_invoices"."tenant_id" = 'acme'`,
+		"The tenant column should never be overwritten")
+}
+
+func TestTenantScopedUpsertRejectedOnMySQL(t *testing.T) {
+	db, captured := newDryRunMySQLDB(t)
+	repo := newTestInvoiceRepository(db).WithContext(WithTenant(context.Background(), "acme"))
+
+	_, err := repo.Upsert(nil, &testInvoice{ID: 1, Number: "F-1"}, UpsertOptions{})
+	assert.ErrorIs(t, err, ErrTenantUpsertUnsupported)
+	assert.Empty(t, captured.sql)
+}
+
+func TestWithoutTenantScope(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := newTestInvoiceRepository(db).WithContext(WithoutTenantScope(context.Background()))
+
+	_, err := repo.FindAll()
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM "test_invoices"`, captured.last())
+}
@@ -0,0 +1,97 @@
+package stdlib
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrStatementTimeoutUnsupported is returned when a transaction with WithStatementTimeout begins on MySQL,
+// which only limits the execution time of the SELECT statements.
+var ErrStatementTimeoutUnsupported = errors.New("statement timeout unsupported on MySQL")
+
+// TransactionOption customizes a single transaction of the TransactionalRepository (e.g. retries or isolation).
+// Options are applied in order, so the last one wins when two of them conflict. The nested transactions
+// run within the outer one, so they ignore the options but WithPanicAsError.
+type TransactionOption func(*transactionOptions)
+
+type transactionOptions struct {
+	retry            *RetryPolicy
+	isolation        sql.IsolationLevel
+	readOnly         bool
+	statementTimeout time.Duration
+	lockTimeout      time.Duration
+	panicAsError     bool
+}
+
+// WithRetry runs the transaction again, from the beginning, when it fails with a retryable error
+// (see RetryPolicy). The function of the transaction must be idempotent, and the option is ignored
+// by the nested transactions since the outer transaction is the one to retry.
+//
+//	err := txRepo.ExecuteInTransaction(transfer, stdlib.WithRetry(stdlib.RetryPolicy{MaxAttempts: 5}))
+func WithRetry(policy RetryPolicy) TransactionOption {
+	return func(o *transactionOptions) {
+		o.retry = &policy
+	}
+}
+
+// WithIsolation runs the transaction with the isolation level, e.g. sql.LevelSerializable,
+// sql.LevelRepeatableRead or sql.LevelReadCommitted. By default the one of the database is used.
+func WithIsolation(level sql.IsolationLevel) TransactionOption {
+	return func(o *transactionOptions) {
+		o.isolation = level
+	}
+}
+
+// ReadOnly runs a read only transaction, the database rejects its writes.
+func ReadOnly() TransactionOption {
+	return func(o *transactionOptions) {
+		o.readOnly = true
+	}
+}
+
+// WithStatementTimeout aborts the statements of the transaction running for longer than timeout.
+// It is applied with SET LOCAL statement_timeout on PostgreSQL, and with the max_statement_time
+// session variable on MariaDB, restored when the transaction commits or rolls back (either through
+// the repository or the *gorm.DB of the transaction). MySQL returns ErrStatementTimeoutUnsupported.
+func WithStatementTimeout(timeout time.Duration) TransactionOption {
+	return func(o *transactionOptions) {
+		o.statementTimeout = timeout
+	}
+}
+
+// WithLockTimeout aborts the statements of the transaction waiting for a lock for longer than timeout.
+// It is applied with SET LOCAL lock_timeout on PostgreSQL, and with the innodb_lock_wait_timeout
+// session variable on MySQL/MariaDB (rounded up to seconds), restored when the transaction commits or rolls back.
+func WithLockTimeout(timeout time.Duration) TransactionOption {
+	return func(o *transactionOptions) {
+		o.lockTimeout = timeout
+	}
+}
+
+// WithPanicAsError returns a panic of the transaction function as a PanicError, holding the panic value
+// and stack trace, once the transaction is rolled back. By default the panic goes on after the rollback.
+func WithPanicAsError() TransactionOption {
+	return func(o *transactionOptions) {
+		o.panicAsError = true
+	}
+}
+
+// Helper function txOptions returns the options of database/sql, nil for the defaults.
+func (o *transactionOptions) txOptions() *sql.TxOptions {
+	if o.isolation == sql.LevelDefault && !o.readOnly {
+		return nil
+	}
+	return &sql.TxOptions{Isolation: o.isolation, ReadOnly: o.readOnly}
+}
+
+// Helper function newTransactionOptions applies the options over the defaults.
+func newTransactionOptions(opts []TransactionOption) *transactionOptions {
+	options := &transactionOptions{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(options)
+		}
+	}
+	return options
+}
@@ -1,13 +1,27 @@
 package stdlib
 
-import "gorm.io/gorm"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+type transactionKey struct{}
+
+// savepoints numbers the savepoints of the nested transactions, so their names never collide.
+var savepoints atomic.Uint64
 
 // TransactionalRepository defines the methods for managing transactions in a database.
 
 type TransactionalRepository interface {
 
 	// BeginTransaction initializes a transaction by returning the transaction context or a possible error
-	BeginTransaction() (*gorm.DB, error)
+	// The transaction can be customized with options, e.g. WithIsolation or WithStatementTimeout.
+	BeginTransaction(opts ...TransactionOption) (*gorm.DB, error)
 
 	// CommitTransaction commits a transaction, returning a possible error
 	CommitTransaction(tx *gorm.DB) error
@@ -15,22 +29,102 @@ type TransactionalRepository interface {
 	RollbackTransaction(tx *gorm.DB) error
 
 	// ExecuteInTransaction executes a function within a transaction context, logging whether there is a possible error
-	ExecuteInTransaction(fn func(tx *gorm.DB) error) error
+	// If the repository was created on a transaction (e.g. NewTransactionalRepository(tx)), the function runs
+	// in a nested transaction of it, see ExecuteInNestedTransaction.
+	// The transaction can be customized with options, e.g. WithRetry.
+	// If the function panics the transaction is rolled back and the panic goes on, see WithPanicAsError.
+	ExecuteInTransaction(fn func(tx *gorm.DB) error, opts ...TransactionOption) error
+
+	// ExecuteInNestedTransaction executes a function within a nested transaction of tx: a SAVEPOINT is created
+	// on tx, and when the function fails (or panics) only its changes are rolled back (ROLLBACK TO SAVEPOINT),
+	// leaving the outer transaction usable; otherwise the savepoint is released and its changes are committed
+	// or rolled back with the outer transaction. If tx is not a transaction (or it is nil, using the connection
+	// of the repository) a new transaction is started as ExecuteInTransaction does.
+	ExecuteInNestedTransaction(tx *gorm.DB, fn func(tx *gorm.DB) error, opts ...TransactionOption) error
+
+	// ExecuteInTransactionContext executes a function within a transaction carried by the context given to it,
+	// so the repositories bound to that context (see AbstractRepository.WithContext) use the transaction
+	// without threading the tx. If ctx already carries a transaction, the function runs in a nested
+	// transaction of it (see ExecuteInNestedTransaction).
+	ExecuteInTransactionContext(ctx context.Context, fn func(ctx context.Context) error, opts ...TransactionOption) error
+}
+
+// ContextWithTransaction returns a copy of ctx carrying the transaction tx,
+// used by the repositories bound to it when no tx is given.
+func ContextWithTransaction(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, transactionKey{}, tx)
+}
+
+// TransactionFromContext returns the transaction carried by ctx, if any.
+func TransactionFromContext(ctx context.Context) (*gorm.DB, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	tx, ok := ctx.Value(transactionKey{}).(*gorm.DB)
+	return tx, ok && tx != nil
 }
 
 type transactionalRepositoryImpl struct {
 	gorm *gorm.DB
 }
 
+// Helper: a MySQL/MariaDB transaction restoring the session variables of its timeouts before it ends,
+// so the connection goes back to the pool unchanged however the transaction is committed or rolled back.
+type sessionTx struct {
+	gorm.ConnPool
+	restore []string
+}
+
+func (t *sessionTx) Commit() error {
+	committer := t.ConnPool.(gorm.TxCommitter)
+	if err := t.restoreSession(); err != nil {
+		return joinErrors(err, committer.Rollback())
+	}
+	return committer.Commit()
+}
+
+func (t *sessionTx) Rollback() error {
+	err := t.restoreSession()
+	return joinErrors(t.ConnPool.(gorm.TxCommitter).Rollback(), err)
+}
+
+// Helper function restoreSession runs the statements restoring the session, once.
+func (t *sessionTx) restoreSession() error {
+	restore := t.restore
+	t.restore = nil
+	for _, statement := range restore {
+		if _, err := t.ExecContext(context.Background(), statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func NewTransactionalRepository(gorm *gorm.DB) TransactionalRepository {
 	return &transactionalRepositoryImpl{gorm: gorm}
 }
 
-func (repo *transactionalRepositoryImpl) BeginTransaction() (*gorm.DB, error) {
-	tx := repo.gorm.Begin()
+func (repo *transactionalRepositoryImpl) BeginTransaction(opts ...TransactionOption) (*gorm.DB, error) {
+	options := newTransactionOptions(opts)
+
+	apply, restore, err := timeoutStatements(repo.gorm.Dialector, options.statementTimeout, options.lockTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := repo.gorm.Begin(options.txOptions())
 	if tx.Error != nil {
 		return nil, tx.Error
 	}
+
+	if len(restore) > 0 {
+		tx.Statement.ConnPool = &sessionTx{ConnPool: tx.Statement.ConnPool, restore: restore}
+	}
+	for _, statement := range apply {
+		if err := tx.Exec(statement).Error; err != nil {
+			return nil, joinErrors(err, repo.RollbackTransaction(tx))
+		}
+	}
 	return tx, nil
 }
 
@@ -42,23 +136,120 @@ func (repo *transactionalRepositoryImpl) RollbackTransaction(tx *gorm.DB) error
 	return tx.Rollback().Error
 }
 
-func (repo *transactionalRepositoryImpl) ExecuteInTransaction(fn func(tx *gorm.DB) error) error {
-	tx, err := repo.BeginTransaction()
+func (repo *transactionalRepositoryImpl) ExecuteInTransaction(fn func(tx *gorm.DB) error, opts ...TransactionOption) error {
+	options := newTransactionOptions(opts)
+	if inTransaction(repo.gorm) {
+		return executeInSavepoint(repo.gorm, fn, options.panicAsError)
+	}
+
+	if options.retry == nil {
+		return repo.executeInTransaction(fn, options, opts)
+	}
+	return options.retry.do(repo.gorm.Statement.Context, func() error {
+		return repo.executeInTransaction(fn, options, opts)
+	})
+}
+
+// Helper function executeInTransaction runs fn within a new transaction, committed if fn succeeds.
+func (repo *transactionalRepositoryImpl) executeInTransaction(fn func(tx *gorm.DB) error, options *transactionOptions, opts []TransactionOption) (err error) {
+	tx, err := repo.BeginTransaction(opts...)
 	if err != nil {
 		return err
 	}
 
-	defer func() {
-		if r := recover(); r != nil || err != nil {
-			repo.RollbackTransaction(tx)
-		}
-	}()
+	panicked := true
+	defer rollbackOnPanic(&panicked, options.panicAsError, func() error {
+		return repo.RollbackTransaction(tx)
+	}, &err)
 
 	err = fn(tx)
+	panicked = false
 	if err != nil {
-		repo.RollbackTransaction(tx)
-		return err
+		return joinErrors(err, repo.RollbackTransaction(tx))
 	}
 
 	return repo.CommitTransaction(tx)
 }
+
+func (repo *transactionalRepositoryImpl) ExecuteInNestedTransaction(tx *gorm.DB, fn func(tx *gorm.DB) error, opts ...TransactionOption) error {
+	if tx == nil {
+		return repo.ExecuteInTransaction(fn, opts...)
+	}
+	if inTransaction(tx) {
+		return executeInSavepoint(tx, fn, newTransactionOptions(opts).panicAsError)
+	}
+	return NewTransactionalRepository(tx).ExecuteInTransaction(fn, opts...)
+}
+
+func (repo *transactionalRepositoryImpl) ExecuteInTransactionContext(ctx context.Context, fn func(ctx context.Context) error, opts ...TransactionOption) error {
+	tx, ok := TransactionFromContext(ctx)
+	if !ok {
+		tx = repo.gorm.WithContext(ctx)
+	}
+
+	return repo.ExecuteInNestedTransaction(tx, func(tx *gorm.DB) error {
+		return fn(ContextWithTransaction(ctx, tx))
+	}, opts...)
+}
+
+// Helper function inTransaction reports whether the connection is a transaction.
+func inTransaction(db *gorm.DB) bool {
+	committer, ok := db.Statement.ConnPool.(gorm.TxCommitter)
+	return ok && committer != nil
+}
+
+// Helper function executeInSavepoint runs fn on the transaction tx within a savepoint, rolling back to it
+// when fn fails or panics (see rollbackOnPanic), and releasing it otherwise.
+func executeInSavepoint(tx *gorm.DB, fn func(tx *gorm.DB) error, panicAsError bool) (err error) {
+	name := fmt.Sprintf("sp_%d", savepoints.Add(1))
+	if err := tx.Session(&gorm.Session{}).SavePoint(name).Error; err != nil {
+		return err
+	}
+
+	rollback := func() error {
+		return tx.Session(&gorm.Session{}).RollbackTo(name).Error
+	}
+	panicked := true
+	defer rollbackOnPanic(&panicked, panicAsError, rollback, &err)
+
+	err = fn(tx)
+	panicked = false
+	if err != nil {
+		return joinErrors(err, rollback())
+	}
+
+	return tx.Session(&gorm.Session{}).Exec("RELEASE SAVEPOINT " + name).Error
+}
+
+// Helper function rollbackOnPanic is deferred to roll back a transaction when fn panics, the panic goes on
+// once rolled back. With panicAsError it is recovered instead, and returned in err as a PanicError.
+func rollbackOnPanic(panicked *bool, panicAsError bool, rollback func() error, err *error) {
+	if !*panicked {
+		return
+	}
+	if !panicAsError {
+		_ = rollback()
+		return
+	}
+
+	// recover returns nil when fn calls runtime.Goexit, which goes on as well
+	r := recover()
+	if r == nil {
+		_ = rollback()
+		return
+	}
+	panicErr := &PanicError{Value: r, Stack: debug.Stack()}
+	*err = joinErrors(panicErr, rollback())
+}
+
+// Helper function joinErrors joins the errors, returning one of them unchanged when the other is nil
+// so that the callers can still compare it with ==.
+func joinErrors(err, other error) error {
+	if other == nil {
+		return err
+	}
+	if err == nil {
+		return other
+	}
+	return errors.Join(err, other)
+}
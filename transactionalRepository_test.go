@@ -0,0 +1,236 @@
+package stdlib
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// Helper: a fake transaction over a dry run connection, recording the statements executed without gorm
+type testTx struct {
+	gorm.ConnPool
+	committed  bool
+	rolledBack bool
+	executed   []string
+}
+
+func (t *testTx) ExecContext(_ context.Context, query string, _ ...interface{}) (sql.Result, error) {
+	t.executed = append(t.executed, query)
+	return nil, nil
+}
+
+func (t *testTx) Commit() error {
+	t.committed = true
+	return nil
+}
+
+func (t *testTx) Rollback() error {
+	t.rolledBack = true
+	return nil
+}
+
+func newDryRunTx(db *gorm.DB) (*gorm.DB, *testTx) {
+	tx := db.WithContext(context.Background())
+	fake := &testTx{ConnPool: tx.Statement.ConnPool}
+	tx.Statement.ConnPool = fake
+	return tx, fake
+}
+
+// Helper: a fake connection beginning fake transactions, recording their options
+type testBeginner struct {
+	gorm.ConnPool
+	options []*sql.TxOptions
+	txs     []*testTx
+}
+
+func (b *testBeginner) BeginTx(_ context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	tx := &testTx{ConnPool: b.ConnPool}
+	b.options = append(b.options, opts)
+	b.txs = append(b.txs, tx)
+	return tx, nil
+}
+
+func newDryRunBeginner(db *gorm.DB) *testBeginner {
+	beginner := &testBeginner{ConnPool: db.Statement.ConnPool}
+	db.Statement.ConnPool = beginner
+	return beginner
+}
+
+func TestTransactionOptions(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	beginner := newDryRunBeginner(db)
+
+	err := NewTransactionalRepository(db).ExecuteInTransaction(func(tx *gorm.DB) error {
+		return tx.Exec("SELECT 1").Error
+	}, WithIsolation(sql.LevelSerializable), ReadOnly(), WithStatementTimeout(2*time.Second), WithLockTimeout(500*time.Microsecond))
+	assert.NoError(t, err)
+	assert.Equal(t, []*sql.TxOptions{{Isolation: sql.LevelSerializable, ReadOnly: true}}, beginner.options)
+	assert.Equal(t, []string{"SET LOCAL statement_timeout = 2000", "SET LOCAL lock_timeout = 1", "SELECT 1"}, captured.sql)
+	assert.True(t, beginner.txs[0].committed)
+
+	_, err = NewTransactionalRepository(db).BeginTransaction()
+	assert.NoError(t, err)
+	assert.Nil(t, beginner.options[1], "Without options the database defaults should be used")
+}
+
+func TestTransactionTimeoutsRestoreMySQLSession(t *testing.T) {
+	db, captured := newDryRunMySQLDB(t)
+	beginner := newDryRunBeginner(db)
+	errFailure := errors.New("failure")
+
+	err := NewTransactionalRepository(db).ExecuteInTransaction(func(tx *gorm.DB) error {
+		return errFailure
+	}, WithStatementTimeout(1500*time.Millisecond), WithLockTimeout(1200*time.Millisecond))
+	assert.Same(t, errFailure, err, "A successful rollback should return the error unchanged")
+	assert.Equal(t, []string{"SET SESSION max_statement_time = 1.5", "SET SESSION innodb_lock_wait_timeout = 2"}, captured.sql)
+	assert.Equal(t, []string{
+		"SET SESSION max_statement_time = DEFAULT",
+		"SET SESSION innodb_lock_wait_timeout = DEFAULT",
+	}, beginner.txs[0].executed, "The session should be restored before the connection is released")
+	assert.True(t, beginner.txs[0].rolledBack)
+
+	// the transaction restores the session even when it is committed with gorm
+	tx, err := NewTransactionalRepository(db).BeginTransaction(WithLockTimeout(time.Second))
+	assert.NoError(t, err)
+	assert.NoError(t, tx.Commit().Error)
+	assert.Equal(t, []string{"SET SESSION innodb_lock_wait_timeout = DEFAULT"}, beginner.txs[1].executed)
+	assert.True(t, beginner.txs[1].committed)
+}
+
+func TestStatementTimeoutUnsupportedOnMySQL(t *testing.T) {
+	db, _ := newDryRunDialectorDB(t, mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(localhost:3306)/test",
+		ServerVersion:             "8.0.36",
+		SkipInitializeWithVersion: true,
+	}))
+	beginner := newDryRunBeginner(db)
+
+	_, err := NewTransactionalRepository(db).BeginTransaction(WithStatementTimeout(time.Second))
+	assert.ErrorIs(t, err, ErrStatementTimeoutUnsupported)
+	assert.Empty(t, beginner.txs, "No transaction should begin")
+}
+
+func TestTransactionRollsBackOnPanic(t *testing.T) {
+	db, _ := newDryRunDB(t)
+	beginner := newDryRunBeginner(db)
+	repo := NewTransactionalRepository(db)
+
+	assert.PanicsWithValue(t, "boom", func() {
+		_ = repo.ExecuteInTransaction(func(tx *gorm.DB) error {
+			panic("boom")
+		})
+	}, "The panic should go on once rolled back")
+	assert.True(t, beginner.txs[0].rolledBack)
+	assert.False(t, beginner.txs[0].committed)
+
+	errCause := errors.New("cause")
+	err := repo.ExecuteInTransaction(func(tx *gorm.DB) error {
+		panic(errCause)
+	}, WithPanicAsError())
+	var panicErr *PanicError
+	assert.ErrorAs(t, err, &panicErr)
+	assert.ErrorIs(t, err, errCause)
+	assert.Contains(t, string(panicErr.Stack), "TestTransactionRollsBackOnPanic")
+	assert.Equal(t, "panic in transaction: cause", err.Error(), "The message should not hold the stack trace")
+	assert.True(t, beginner.txs[1].rolledBack)
+}
+
+func TestTransactionJoinsRollbackErrors(t *testing.T) {
+	db, _ := newDryRunDB(t)
+	beginner := newDryRunBeginner(db)
+	errFailure := errors.New("failure")
+
+	err := NewTransactionalRepository(db).ExecuteInTransaction(func(tx *gorm.DB) error {
+		// the transaction is already over, so its rollback fails
+		tx.Statement.ConnPool = beginner.ConnPool
+		return errFailure
+	})
+	assert.ErrorIs(t, err, errFailure)
+	assert.ErrorIs(t, err, gorm.ErrInvalidTransaction)
+}
+
+func TestNestedTransactionReleasesSavepoint(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	tx, fake := newDryRunTx(db)
+	repo := NewTransactionalRepository(db)
+
+	err := repo.ExecuteInNestedTransaction(tx, func(tx *gorm.DB) error {
+		return tx.Exec("SELECT 1").Error
+	})
+	assert.NoError(t, err)
+	assert.Len(t, captured.sql, 3)
+	assert.Regexp(t, `^SAVEPOINT sp_\d+$`, captured.sql[0])
+	assert.Equal(t, "SELECT 1", captured.sql[1])
+	assert.Equal(t, "RELEASE "+captured.sql[0], captured.sql[2])
+	assert.False(t, fake.committed, "The outer transaction should be left to its owner")
+}
+
+func TestNestedTransactionRollsBackToSavepoint(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	tx, fake := newDryRunTx(db)
+	errInner := errors.New("inner failure")
+
+	// the repository created on the transaction nests its transactions too
+	err := NewTransactionalRepository(tx).ExecuteInTransaction(func(tx *gorm.DB) error {
+		return errInner
+	})
+	assert.Same(t, errInner, err)
+	assert.Len(t, captured.sql, 2)
+	assert.Equal(t, "ROLLBACK TO "+captured.sql[0], captured.sql[1])
+	assert.False(t, fake.rolledBack, "Only the savepoint should be rolled back")
+
+	assert.Panics(t, func() {
+		_ = NewTransactionalRepository(db).ExecuteInNestedTransaction(tx, func(tx *gorm.DB) error {
+			panic("boom")
+		})
+	})
+	assert.Regexp(t, `^ROLLBACK TO SAVEPOINT sp_\d+$`, captured.last(), "A panic should roll back to the savepoint")
+	assert.NotEqual(t, captured.sql[0], captured.sql[2], "Every savepoint should have its own name")
+
+	err = NewTransactionalRepository(tx).ExecuteInTransaction(func(tx *gorm.DB) error {
+		panic("boom")
+	}, WithPanicAsError())
+	assert.ErrorContains(t, err, "panic in transaction: boom")
+	assert.Regexp(t, `^ROLLBACK TO SAVEPOINT sp_\d+$`, captured.last())
+}
+
+func TestRepositoriesUseTheContextTransaction(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	tx, fake := newDryRunTx(db)
+	accounts := newTestAccountRepository(db)
+
+	var onTransaction []bool
+	_ = db.Callback().Create().After("gorm:create").Register("test:conn", func(db *gorm.DB) {
+		onTransaction = append(onTransaction, db.Statement.ConnPool == fake)
+	})
+
+	ctx := ContextWithTransaction(context.Background(), tx)
+	err := NewTransactionalRepository(db).ExecuteInTransactionContext(ctx, func(ctx context.Context) error {
+		_, err := accounts.WithContext(ctx).Create(nil, &testAccount{Username: "john"})
+		return err
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{true}, onTransaction, "The repository should run on the transaction of the context")
+	assert.Len(t, captured.sql, 3, "The context transaction should be nested in a savepoint")
+	assert.Contains(t, captured.sql[1], `INSERT INTO "test_accounts"`)
+
+	_, err = accounts.Create(nil, &testAccount{Username: "doe"})
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{true, false}, onTransaction, "Without the context the repository should use its connection")
+}
+
+func TestTransactionFromContext(t *testing.T) {
+	_, ok := TransactionFromContext(context.Background())
+	assert.False(t, ok)
+
+	db, _ := newDryRunDB(t)
+	tx, ok := TransactionFromContext(ContextWithTransaction(context.Background(), db))
+	assert.True(t, ok)
+	assert.Same(t, db, tx)
+}
@@ -0,0 +1,175 @@
+package stdlib
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Helper: the error of a tenant scoped upsert conflicting with the row of another tenant
+var errTenantConflict = fmt.Errorf("%w: the conflicting row belongs to another tenant", ErrRecordConflict)
+
+// UpsertOptions configures the conflict handling of Upsert and UpsertMany.
+type UpsertOptions struct {
+	// ConflictColumns are the unique columns that detect the conflict, by default the primary key.
+	// Only used by PostgreSQL, MySQL/MariaDB resolve the conflict with any unique index of the table.
+	ConflictColumns []string
+
+	// UpdateColumns are the columns overwritten when there is a conflict, by default all of them.
+	// The tenant column of a tenant scoped repository and the creator of an Auditable entity are never overwritten.
+	// An upsert left without columns to update by them fails.
+	UpdateColumns []string
+
+	// BatchSize is the number of rows inserted per statement by UpsertMany, DefaultBatchSize if it is not positive.
+	BatchSize int
+}
+
+// Upsert implements AbstractRepository.
+func (repo *abstractRepositoryImpl[T, K]) Upsert(tx *gorm.DB, entity T, opts UpsertOptions) (T, error) {
+	db := repo.transCheck(tx)
+
+	onConflict, scoped, err := repo.onConflict(db, opts)
+	if err != nil {
+		var zeroValue T
+		return zeroValue, err
+	}
+
+	if err := repo.stampTenant(db, &entity); err != nil {
+		var zeroValue T
+		return zeroValue, err
+	}
+	repo.stampActor(db, true, &entity)
+
+	result := db.Clauses(onConflict).Create(&entity)
+	if result.Error != nil {
+		var zeroValue T
+		return zeroValue, repo.translate(result.Error)
+	}
+	// the update of a tenant scoped upsert skips the rows of the other tenants
+	if scoped && result.RowsAffected == 0 {
+		var zeroValue T
+		return zeroValue, errTenantConflict
+	}
+
+	return entity, nil
+}
+
+// UpsertMany implements AbstractRepository.
+func (repo *abstractRepositoryImpl[T, K]) UpsertMany(tx *gorm.DB, entities []T, opts UpsertOptions) ([]T, error) {
+	if len(entities) == 0 {
+		return entities, nil
+	}
+
+	batchSize := opts.BatchSize
+	if batchSize < 1 {
+		batchSize = DefaultBatchSize
+	}
+
+	db := repo.transCheck(tx)
+
+	onConflict, scoped, err := repo.onConflict(db, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repo.stampTenants(db, entities); err != nil {
+		return nil, err
+	}
+	repo.stampActors(db, entities)
+
+	result := db.Clauses(onConflict).CreateInBatches(&entities, batchSize)
+	if result.Error != nil {
+		return nil, repo.translate(result.Error)
+	}
+	if scoped && result.RowsAffected < int64(len(entities)) {
+		return nil, errTenantConflict
+	}
+
+	return entities, nil
+}
+
+// Helper function onConflict validates the upsert columns against the schema of T and builds the conflict
+// clause for the driver of the connection, reporting whether it is restricted to the tenant of the context.
+func (repo *abstractRepositoryImpl[T, K]) onConflict(db *gorm.DB, opts UpsertOptions) (clause.OnConflict, bool, error) {
+	sch, err := repo.schema()
+	if err != nil {
+		return clause.OnConflict{}, false, err
+	}
+
+	conflictColumns := sch.PrimaryFieldDBNames
+	if len(opts.ConflictColumns) > 0 {
+		if conflictColumns, err = resolveColumns(sch, opts.ConflictColumns); err != nil {
+			return clause.OnConflict{}, false, err
+		}
+	}
+
+	updateColumns, err := resolveColumns(sch, opts.UpdateColumns)
+	if err != nil {
+		return clause.OnConflict{}, false, err
+	}
+
+	excluded, err := repo.upsertExcludedColumns(db)
+	if err != nil {
+		return clause.OnConflict{}, false, err
+	}
+	if len(excluded) == 0 {
+		return repo.tenantGuard(db, onConflictClause(db.Dialector.Name(), conflictColumns, updateColumns))
+	}
+
+	if len(updateColumns) == 0 {
+		updateColumns = upsertColumns(sch)
+	}
+	updateColumns = slices.DeleteFunc(updateColumns, func(column string) bool {
+		return slices.Contains(excluded, column)
+	})
+
+	// without columns the conflict would do nothing, which cannot be told from a conflict with another tenant
+	if len(updateColumns) == 0 {
+		return clause.OnConflict{}, false, fmt.Errorf("no column to update on conflict, %s are never overwritten", strings.Join(excluded, ", "))
+	}
+	return repo.tenantGuard(db, onConflictClause(db.Dialector.Name(), conflictColumns, updateColumns))
+}
+
+// Helper function upsertExcludedColumns returns the columns an upsert never overwrites on conflict,
+// the tenant column of a tenant scoped repository and the creator of an Auditable entity.
+func (repo *abstractRepositoryImpl[T, K]) upsertExcludedColumns(db *gorm.DB) ([]string, error) {
+	var excluded []string
+
+	if _, ok := asAuditable(new(T)); ok {
+		if createdBy := repo.auditableColumn(createdByField); createdBy != "" {
+			excluded = append(excluded, createdBy)
+		}
+	}
+
+	if _, ok, err := repo.tenant(db); err != nil {
+		return nil, err
+	} else if ok {
+		field, err := repo.tenantField()
+		if err != nil {
+			return nil, err
+		}
+		excluded = append(excluded, field.DBName)
+	}
+	return excluded, nil
+}
+
+// Helper function upsertColumns returns the columns overwritten on conflict by default,
+// the ones gorm updates with clause.OnConflict{UpdateAll: true}.
+func upsertColumns(sch *schema.Schema) []string {
+	var columns []string
+	for _, name := range sch.DBNames {
+		field := sch.LookUpField(name)
+		if field.PrimaryKey || !field.Creatable || field.AutoCreateTime > 0 {
+			continue
+		}
+		if field.HasDefaultValue && field.DefaultValueInterface == nil && !strings.EqualFold(field.DefaultValue, "NULL") {
+			continue
+		}
+		columns = append(columns, name)
+	}
+	return columns
+}
@@ -0,0 +1,38 @@
+package stdlib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpsertPostgres(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := newTestAccountRepository(db)
+
+	_, err := repo.Upsert(nil, &testAccount{ID: 1, Username: "john"}, UpsertOptions{
+		ConflictColumns: []string{"Email"},
+		UpdateColumns:   []string{"username"},
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, captured.last(), `ON CONFLICT ("email") DO UPDATE SET "username"="excluded"."username"`)
+
+	_, err = repo.Upsert(nil, &testAccount{ID: 1, Username: "john"}, UpsertOptions{})
+	assert.NoError(t, err)
+	assert.Contains(t, captured.last(), `ON CONFLICT ("id") DO UPDATE SET`)
+
+	_, err = repo.Upsert(nil, &testAccount{}, UpsertOptions{UpdateColumns: []string{"password"}})
+	assert.ErrorIs(t, err, ErrUnknownColumn)
+}
+
+func TestUpsertManyMySQL(t *testing.T) {
+	db, captured := newDryRunMySQLDB(t)
+	repo := newTestAccountRepository(db)
+
+	_, err := repo.UpsertMany(nil, []*testAccount{{ID: 1, Username: "a"}, {ID: 2, Username: "b"}}, UpsertOptions{
+		ConflictColumns: []string{"email"},
+		UpdateColumns:   []string{"username"},
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, captured.last(), "ON DUPLICATE KEY UPDATE `username`=VALUES(`username`)")
+}
@@ -0,0 +1,59 @@
+package stdlib
+
+import "gorm.io/gorm"
+
+// Field storing the version of the Versioned entities, and the column used when T has no such field.
+const (
+	versionField  string = "Version"
+	versionColumn string = "version"
+)
+
+// Versioned is implemented by the entities using optimistic locking.
+// The version is stored in the column of the Version field (the `version` column if T has none),
+// and Update/UpdateSpecific only modify the row when the version matches, incrementing it.
+// UpdateSpecific checks the version given in its fields, by column or field name.
+//
+//	type Account struct {
+//		ID      uint
+//		Version uint `gorm:"not null;default:1"`
+//	}
+//
+//	func (a *Account) GetVersion() uint        { return a.Version }
+//	func (a *Account) SetVersion(version uint) { a.Version = version }
+type Versioned interface {
+	GetVersion() uint
+	SetVersion(version uint)
+}
+
+// Helper function asVersioned returns the entity as Versioned, either by value or by pointer.
+func asVersioned[T any](entity *T) (Versioned, bool) {
+	if versioned, ok := any(*entity).(Versioned); ok {
+		return versioned, true
+	}
+	versioned, ok := any(entity).(Versioned)
+	return versioned, ok
+}
+
+// Helper function versionKeys returns the column of the version and the name of its field, empty if T has none.
+func (repo *abstractRepositoryImpl[T, K]) versionKeys() (string, string) {
+	sch, err := repo.schema()
+	if err != nil {
+		return versionColumn, ""
+	}
+	if field := sch.LookUpField(versionField); field != nil && field.DBName != "" {
+		return field.DBName, field.Name
+	}
+	return versionColumn, ""
+}
+
+// Helper function staleOrNotFound explains why a versioned update did not affect any row.
+func (repo *abstractRepositoryImpl[T, K]) staleOrNotFound(tx *gorm.DB, id K) error {
+	exists, err := repo.existsByID(tx, id, false)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrRecordNotFound
+	}
+	return ErrStaleEntity
+}
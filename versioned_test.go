@@ -0,0 +1,66 @@
+package stdlib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testDocument struct {
+	ID      uint `gorm:"primaryKey"`
+	Title   string
+	Version uint
+}
+
+func (d *testDocument) GetID() uint {
+	return d.ID
+}
+
+func (d *testDocument) GetVersion() uint {
+	return d.Version
+}
+
+func (d *testDocument) SetVersion(version uint) {
+	d.Version = version
+}
+
+type testDocumentRepository struct {
+	AbstractRepository[*testDocument, uint]
+}
+
+func newTestDocumentRepository(gormDB *gorm.DB) *testDocumentRepository {
+	repo := &testDocumentRepository{}
+	repo.AbstractRepository = CreateRepository(gormDB, repo)
+	return repo
+}
+
+func TestUpdateVersionedChecksVersion(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := newTestDocumentRepository(db)
+
+	document := &testDocument{Title: "draft", Version: 3}
+	err := repo.Update(nil, 1, document)
+
+	// a dry run never affects rows, so the entity is reported as missing
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.Contains(t, captured.sql[0], `UPDATE "test_documents" SET "title"='draft',"version"=4 WHERE "test_documents"."id" = 1 AND version = 3`)
+	assert.Equal(t, uint(3), document.Version, "The version should be restored when the update fails")
+}
+
+func TestUpdateSpecificVersionedIncrementsVersion(t *testing.T) {
+	db, captured := newDryRunDB(t)
+	repo := newTestDocumentRepository(db)
+
+	fields := map[string]interface{}{"title": "final", "version": 3}
+	_ = repo.UpdateSpecific(nil, 1, fields)
+
+	assert.Contains(t, captured.sql[0], `"version"=version + 1`)
+	assert.Contains(t, captured.sql[0], `WHERE "test_documents"."id" = 1 AND version = 3`)
+	assert.Equal(t, 3, fields["version"], "The given map should not be modified")
+
+	captured.sql = nil
+	_ = repo.UpdateSpecific(nil, 1, map[string]interface{}{"Version": 3, "title": "final"})
+	assert.Contains(t, captured.sql[0], `UPDATE "test_documents" SET "title"='final',"version"=version + 1 WHERE "test_documents"."id" = 1 AND version = 3`,
+		"The version should be checked when given by field name")
+}